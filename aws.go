@@ -8,7 +8,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -94,7 +97,9 @@ func (s *AWSService) List(ctx context.Context) ([]*AWSAccount, *Response, error)
 	endpoint.WriteString(baseURL)
 	endpoint.WriteString(awsAccountPath)
 
-	req, err := s.client.request(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceAWS, "List", "")
+
+	req, err := s.client.request(opCtx, http.MethodGet, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
@@ -119,6 +124,12 @@ func (s *AWSService) List(ctx context.Context) ([]*AWSAccount, *Response, error)
 
 // Create registers a new AWS account with Cloudcraft.
 //
+// If an attempt fails in a way that leaves unclear whether the account was
+// registered, such as a gateway timeout, Create looks for an account with the
+// same name and role ARN registered since the call started before retrying,
+// and returns it if found. Use ContextWithIdempotencyKey to retry such failures
+// unconditionally.
+//
 // [API reference].
 //
 // [API reference]: https://developers.cloudcraft.co/#51c4726e-ce1a-4e16-8b3f-f15dcee0aebe
@@ -154,12 +165,32 @@ func (s *AWSService) Create(ctx context.Context, account *AWSAccount) (*AWSAccou
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
-	req, err := s.client.request(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
+	var (
+		start        = time.Now()
+		existing     *AWSAccount
+		existingResp *Response
+	)
+
+	opCtx := withOperation(ctx, serviceAWS, "Create", "")
+
+	createCtx := withDuplicateCheck(opCtx, func(checkCtx context.Context) (bool, error) {
+		var err error
+
+		existing, existingResp, err = s.findCreated(checkCtx, account, start)
+
+		return existing != nil, err
+	})
+
+	req, err := s.client.request(createCtx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
 	resp, err := s.client.do(req)
+	if errors.Is(err, errDuplicateFound) {
+		return existing, existingResp, nil
+	}
+
 	if err != nil {
 		return nil, resp, fmt.Errorf("%w", err)
 	}
@@ -215,7 +246,9 @@ func (s *AWSService) Update(ctx context.Context, account *AWSAccount) (*Response
 		return nil, fmt.Errorf("%w", err)
 	}
 
-	req, err := s.client.request(ctx, http.MethodPut, endpoint.String(), bytes.NewReader(payload))
+	opCtx := withOperation(ctx, serviceAWS, "Update", account.ID)
+
+	req, err := s.client.request(opCtx, http.MethodPut, endpoint.String(), bytes.NewReader(payload))
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
@@ -254,7 +287,9 @@ func (s *AWSService) Delete(ctx context.Context, id string) (*Response, error) {
 	endpoint.WriteByte('/')
 	endpoint.WriteString(id)
 
-	req, err := s.client.request(ctx, http.MethodDelete, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceAWS, "Delete", id)
+
+	req, err := s.client.request(opCtx, http.MethodDelete, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
@@ -278,16 +313,66 @@ func (s *AWSService) Snapshot(
 	id, region, format string,
 	params *SnapshotParams,
 ) ([]byte, *Response, error) {
+	req, err := s.snapshotRequest(ctx, id, region, format, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return nil, resp, fmt.Errorf("%w", err)
+	}
+
+	return resp.Body, resp, nil
+}
+
+// SnapshotTo scans and render a region of an AWS account into a blueprint
+// in JSON, SVG, PNG, PDF or MxGraph format and writes it to w as it is
+// received, without holding the whole snapshot in memory. If an error occurs
+// after the response started, w may have received part of the body.
+//
+// [API reference].
+//
+// [API reference]: https://developers.cloudcraft.co/#13e7daaf-e22a-42c6-b6bc-e34a24f05e60
+func (s *AWSService) SnapshotTo(
+	ctx context.Context,
+	w io.Writer,
+	id, region, format string,
+	params *SnapshotParams,
+) (*Response, error) {
+	if w == nil {
+		return nil, ErrNilWriter
+	}
+
+	req, err := s.snapshotRequest(ctx, id, region, format, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.stream(req, w)
+	if err != nil {
+		return resp, fmt.Errorf("%w", err)
+	}
+
+	return resp, nil
+}
+
+// snapshotRequest returns the request used by Snapshot and SnapshotTo.
+func (s *AWSService) snapshotRequest(
+	ctx context.Context,
+	id, region, format string,
+	params *SnapshotParams,
+) (*http.Request, error) {
 	if ctx == nil {
-		return nil, nil, ErrNilContext
+		return nil, ErrNilContext
 	}
 
 	if id == "" {
-		return nil, nil, ErrEmptyAccountID
+		return nil, ErrEmptyAccountID
 	}
 
 	if region == "" {
-		return nil, nil, ErrEmptyRegion
+		return nil, ErrEmptyRegion
 	}
 
 	if format == "" {
@@ -319,22 +404,19 @@ func (s *AWSService) Snapshot(
 
 	u, err := url.Parse(endpoint.String())
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, fmt.Errorf("%w", err)
 	}
 
 	u.RawQuery = params.query().Encode()
 
-	req, err := s.client.request(ctx, http.MethodGet, u.String(), http.NoBody)
-	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
-	}
+	opCtx := withOperation(ctx, serviceAWS, "Snapshot", id)
 
-	resp, err := s.client.do(req)
+	req, err := s.client.request(opCtx, http.MethodGet, u.String(), http.NoBody)
 	if err != nil {
-		return nil, resp, fmt.Errorf("%w", err)
+		return nil, fmt.Errorf("%w", err)
 	}
 
-	return resp.Body, resp, nil
+	return req, nil
 }
 
 // IAMParameters list all parameters required for registering a new IAM Role in
@@ -359,7 +441,9 @@ func (s *AWSService) IAMParameters(ctx context.Context) (*IAMParams, *Response,
 	endpoint.WriteString(awsAccountPath)
 	endpoint.WriteString("/iamParameters")
 
-	req, err := s.client.request(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceAWS, "IAMParameters", "")
+
+	req, err := s.client.request(opCtx, http.MethodGet, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
@@ -398,7 +482,9 @@ func (s *AWSService) IAMPolicy(ctx context.Context) (*IAMPolicy, *Response, erro
 	endpoint.WriteString(awsAccountPath)
 	endpoint.WriteString("/iamParameters/policy/minimal")
 
-	req, err := s.client.request(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceAWS, "IAMPolicy", "")
+
+	req, err := s.client.request(opCtx, http.MethodGet, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
@@ -415,3 +501,56 @@ func (s *AWSService) IAMPolicy(ctx context.Context) (*IAMPolicy, *Response, erro
 
 	return result, resp, nil
 }
+
+// findCreated looks for an AWS account registered by a previous attempt of a
+// Create call started at the given time, by name, role ARN, creator and
+// creation time.
+func (s *AWSService) findCreated(
+	ctx context.Context,
+	account *AWSAccount,
+	start time.Time,
+) (*AWSAccount, *Response, error) {
+	creator, err := s.client.creatorID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accounts, resp, err := s.List(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	for _, candidate := range accounts {
+		if candidate.Name == account.Name &&
+			candidate.RoleARN == account.RoleARN &&
+			candidate.CreatorID == creator &&
+			createdSince(candidate.CreatedAt, start) {
+			return candidate, resp, nil
+		}
+	}
+
+	return nil, resp, nil
+}
+
+// plainAWSAccount has the fields of AWSAccount without its methods, to format
+// it without recursion.
+type plainAWSAccount AWSAccount
+
+// LogValue implements the slog.LogValuer interface. The external ID is
+// redacted.
+func (a AWSAccount) LogValue() slog.Value { //nolint:gocritic // Value receiver so that values are redacted too.
+	return slog.GroupValue(
+		slog.String("id", a.ID),
+		slog.String("name", a.Name),
+		slog.String("roleArn", a.RoleARN),
+		slog.String("externalId", redact(a.ExternalID)),
+	)
+}
+
+// Format implements the fmt.Formatter interface. The external ID is redacted.
+func (a AWSAccount) Format(f fmt.State, verb rune) { //nolint:gocritic // Value receiver so that values are redacted too.
+	plain := plainAWSAccount(a)
+	plain.ExternalID = redact(plain.ExternalID)
+
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain)
+}
@@ -408,7 +408,7 @@ func TestAWSService_Update(t *testing.T) {
 				t.Fatalf("AWS().Update() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			if !tt.wantErr && !reflect.DeepEqual(xtesting.NormalizeResponse(got), xtesting.NormalizeResponse(tt.want)) {
 				t.Fatalf("AWS().Update() = %v, want %v", got, tt.want)
 			}
 		})
@@ -495,7 +495,7 @@ func TestAWSService_Delete(t *testing.T) {
 				t.Fatalf("AWS().Delete() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			if !tt.wantErr && !reflect.DeepEqual(xtesting.NormalizeResponse(got), xtesting.NormalizeResponse(tt.want)) {
 				t.Fatalf("AWS().Delete() = %v, want %v", got, tt.want)
 			}
 		})
@@ -8,7 +8,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -80,7 +83,9 @@ func (s *AzureService) List(ctx context.Context) ([]*AzureAccount, *Response, er
 	endpoint.WriteString(baseURL)
 	endpoint.WriteString(azureAccountPath)
 
-	req, err := s.client.request(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceAzure, "List", "")
+
+	req, err := s.client.request(opCtx, http.MethodGet, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
@@ -105,6 +110,12 @@ func (s *AzureService) List(ctx context.Context) ([]*AzureAccount, *Response, er
 
 // Create registers a new Azure account with Cloudcraft.
 //
+// If an attempt fails in a way that leaves unclear whether the account was
+// registered, such as a gateway timeout, Create looks for an account with the
+// same name, application, directory and subscription registered since the call
+// started before retrying, and returns it if found. Use
+// ContextWithIdempotencyKey to retry such failures unconditionally.
+//
 // [API reference].
 //
 // [API reference]: https://developers.cloudcraft.co/#09a9a67d-c807-45c1-b8a8-f5a6df08da12
@@ -152,12 +163,32 @@ func (s *AzureService) Create(ctx context.Context, account *AzureAccount) (*Azur
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
-	req, err := s.client.request(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
+	var (
+		start        = time.Now()
+		existing     *AzureAccount
+		existingResp *Response
+	)
+
+	opCtx := withOperation(ctx, serviceAzure, "Create", "")
+
+	createCtx := withDuplicateCheck(opCtx, func(checkCtx context.Context) (bool, error) {
+		var err error
+
+		existing, existingResp, err = s.findCreated(checkCtx, account, start)
+
+		return existing != nil, err
+	})
+
+	req, err := s.client.request(createCtx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
 	resp, err := s.client.do(req)
+	if errors.Is(err, errDuplicateFound) {
+		return existing, existingResp, nil
+	}
+
 	if err != nil {
 		return nil, resp, fmt.Errorf("%w", err)
 	}
@@ -225,7 +256,9 @@ func (s *AzureService) Update(ctx context.Context, account *AzureAccount) (*Resp
 		return nil, fmt.Errorf("%w", err)
 	}
 
-	req, err := s.client.request(ctx, http.MethodPut, endpoint.String(), bytes.NewReader(payload))
+	opCtx := withOperation(ctx, serviceAzure, "Update", account.ID)
+
+	req, err := s.client.request(opCtx, http.MethodPut, endpoint.String(), bytes.NewReader(payload))
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
@@ -264,7 +297,9 @@ func (s *AzureService) Delete(ctx context.Context, id string) (*Response, error)
 	endpoint.WriteByte('/')
 	endpoint.WriteString(id)
 
-	req, err := s.client.request(ctx, http.MethodDelete, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceAzure, "Delete", id)
+
+	req, err := s.client.request(opCtx, http.MethodDelete, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
@@ -288,16 +323,66 @@ func (s *AzureService) Snapshot(
 	id, region, format string,
 	params *SnapshotParams,
 ) ([]byte, *Response, error) {
+	req, err := s.snapshotRequest(ctx, id, region, format, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return nil, resp, fmt.Errorf("%w", err)
+	}
+
+	return resp.Body, resp, nil
+}
+
+// SnapshotTo scans and render a region of an Azure account into a blueprint
+// in JSON, SVG, PNG, PDF or MxGraph format and writes it to w as it is
+// received, without holding the whole snapshot in memory. If an error occurs
+// after the response started, w may have received part of the body.
+//
+// [API reference].
+//
+// [API reference]: https://developers.cloudcraft.co/#e687cfa9-f181-4eaf-bf76-f167235fa4fe
+func (s *AzureService) SnapshotTo(
+	ctx context.Context,
+	w io.Writer,
+	id, region, format string,
+	params *SnapshotParams,
+) (*Response, error) {
+	if w == nil {
+		return nil, ErrNilWriter
+	}
+
+	req, err := s.snapshotRequest(ctx, id, region, format, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.stream(req, w)
+	if err != nil {
+		return resp, fmt.Errorf("%w", err)
+	}
+
+	return resp, nil
+}
+
+// snapshotRequest returns the request used by Snapshot and SnapshotTo.
+func (s *AzureService) snapshotRequest(
+	ctx context.Context,
+	id, region, format string,
+	params *SnapshotParams,
+) (*http.Request, error) {
 	if ctx == nil {
-		return nil, nil, ErrNilContext
+		return nil, ErrNilContext
 	}
 
 	if id == "" {
-		return nil, nil, ErrEmptyAccountID
+		return nil, ErrEmptyAccountID
 	}
 
 	if region == "" {
-		return nil, nil, ErrEmptyRegion
+		return nil, ErrEmptyRegion
 	}
 
 	if format == "" {
@@ -329,20 +414,75 @@ func (s *AzureService) Snapshot(
 
 	u, err := url.Parse(endpoint.String())
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, fmt.Errorf("%w", err)
 	}
 
 	u.RawQuery = params.query().Encode()
 
-	req, err := s.client.request(ctx, http.MethodGet, u.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceAzure, "Snapshot", id)
+
+	req, err := s.client.request(opCtx, http.MethodGet, u.String(), http.NoBody)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, fmt.Errorf("%w", err)
 	}
 
-	resp, err := s.client.do(req)
+	return req, nil
+}
+
+// findCreated looks for an Azure account registered by a previous attempt of a
+// Create call started at the given time, by name, application, directory,
+// subscription, creator and creation time.
+func (s *AzureService) findCreated(
+	ctx context.Context,
+	account *AzureAccount,
+	start time.Time,
+) (*AzureAccount, *Response, error) {
+	creator, err := s.client.creatorID(ctx)
 	if err != nil {
-		return nil, resp, fmt.Errorf("%w", err)
+		return nil, nil, err
 	}
 
-	return resp.Body, resp, nil
+	accounts, resp, err := s.List(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	for _, candidate := range accounts {
+		if candidate.Name == account.Name &&
+			candidate.ApplicationID == account.ApplicationID &&
+			candidate.DirectoryID == account.DirectoryID &&
+			candidate.SubscriptionID == account.SubscriptionID &&
+			candidate.CreatorID == creator &&
+			createdSince(candidate.CreatedAt, start) {
+			return candidate, resp, nil
+		}
+	}
+
+	return nil, resp, nil
+}
+
+// plainAzureAccount has the fields of AzureAccount without its methods, to
+// format it without recursion.
+type plainAzureAccount AzureAccount
+
+// LogValue implements the slog.LogValuer interface. The client secret is
+// redacted.
+func (a AzureAccount) LogValue() slog.Value { //nolint:gocritic // Value receiver so that values are redacted too.
+	return slog.GroupValue(
+		slog.String("id", a.ID),
+		slog.String("name", a.Name),
+		slog.String("applicationId", a.ApplicationID),
+		slog.String("directoryId", a.DirectoryID),
+		slog.String("subscriptionId", a.SubscriptionID),
+		slog.String("clientSecret", redact(a.ClientSecret)),
+	)
+}
+
+// Format implements the fmt.Formatter interface. The client secret is
+// redacted.
+func (a AzureAccount) Format(f fmt.State, verb rune) { //nolint:gocritic // Value receiver so that values are redacted too.
+	plain := plainAzureAccount(a)
+	plain.ClientSecret = redact(plain.ClientSecret)
+
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain)
 }
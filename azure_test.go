@@ -517,7 +517,7 @@ func TestAzureService_Update(t *testing.T) {
 				t.Fatalf("Azure.Update() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			if !tt.wantErr && !reflect.DeepEqual(xtesting.NormalizeResponse(got), xtesting.NormalizeResponse(tt.want)) {
 				t.Fatalf("Azure.Update() = %v, want %v", got, tt.want)
 			}
 		})
@@ -603,7 +603,7 @@ func TestAzureService_Delete(t *testing.T) {
 				t.Fatalf("Azure.Delete() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			if !tt.wantErr && !reflect.DeepEqual(xtesting.NormalizeResponse(got), xtesting.NormalizeResponse(tt.want)) {
 				t.Fatalf("Azure.Delete() = %v, want %v", got, tt.want)
 			}
 		})
@@ -8,7 +8,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -223,24 +225,26 @@ func (s *BlueprintService) List(ctx context.Context) ([]*Blueprint, *Response, e
 	endpoint.WriteString(baseURL)
 	endpoint.WriteString(blueprintPath)
 
-	req, err := s.client.request(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceBlueprint, "List", "")
+
+	req, err := s.client.request(opCtx, http.MethodGet, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
 	ret, err := s.client.do(req)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, ret, fmt.Errorf("%w", err)
 	}
 
 	var result map[string][]*Blueprint
 	if err := json.Unmarshal(ret.Body, &result); err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, ret, fmt.Errorf("%w", err)
 	}
 
 	blueprints, ok := result["blueprints"]
 	if !ok {
-		return nil, nil, fmt.Errorf("%w", ErrBlueprintKey)
+		return nil, ret, fmt.Errorf("%w", ErrBlueprintKey)
 	}
 
 	return blueprints, ret, nil
@@ -271,19 +275,21 @@ func (s *BlueprintService) Get(ctx context.Context, id string) (*Blueprint, *Res
 	endpoint.WriteString(blueprintPath)
 	endpoint.WriteString("/" + id)
 
-	req, err := s.client.request(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceBlueprint, "Get", id)
+
+	req, err := s.client.request(opCtx, http.MethodGet, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
 	resp, err := s.client.do(req)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, resp, fmt.Errorf("%w", err)
 	}
 
 	var result *Blueprint
 	if err := json.Unmarshal(resp.Body, &result); err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, resp, fmt.Errorf("%w", err)
 	}
 
 	return result, resp, nil
@@ -291,6 +297,11 @@ func (s *BlueprintService) Get(ctx context.Context, id string) (*Blueprint, *Res
 
 // Create creates a new blueprint.
 //
+// If an attempt fails in a way that leaves unclear whether the blueprint was
+// created, such as a gateway timeout, Create looks for a blueprint with the
+// same name created since the call started before retrying, and returns it if
+// found. Use ContextWithIdempotencyKey to retry such failures unconditionally.
+//
 // [API reference].
 //
 // [API reference]: https://developers.cloudcraft.co/#d72c9b37-9f03-4c24-98d0-92971493780f
@@ -318,19 +329,41 @@ func (s *BlueprintService) Create(ctx context.Context, blueprint *Blueprint) (*B
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
-	req, err := s.client.request(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
+	var (
+		start        = time.Now()
+		existing     *Blueprint
+		existingResp *Response
+	)
+
+	createCtx := withOperation(ctx, serviceBlueprint, "Create", "")
+
+	if blueprintName(blueprint) != "" {
+		createCtx = withDuplicateCheck(createCtx, func(checkCtx context.Context) (bool, error) {
+			var err error
+
+			existing, existingResp, err = s.findCreated(checkCtx, blueprint, start)
+
+			return existing != nil, err
+		})
+	}
+
+	req, err := s.client.request(createCtx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w", err)
 	}
 
 	resp, err := s.client.do(req)
+	if errors.Is(err, errDuplicateFound) {
+		return existing, existingResp, nil
+	}
+
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, resp, fmt.Errorf("%w", err)
 	}
 
 	var result *Blueprint
 	if err := json.Unmarshal(resp.Body, &result); err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, resp, fmt.Errorf("%w", err)
 	}
 
 	return result, resp, nil
@@ -370,7 +403,9 @@ func (s *BlueprintService) Update(ctx context.Context, blueprint *Blueprint, eta
 		return nil, fmt.Errorf("%w", err)
 	}
 
-	req, err := s.client.request(ctx, http.MethodPut, endpoint.String(), bytes.NewReader(payload))
+	opCtx := withOperation(ctx, serviceBlueprint, "Update", blueprint.ID)
+
+	req, err := s.client.request(opCtx, http.MethodPut, endpoint.String(), bytes.NewReader(payload))
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
@@ -381,7 +416,7 @@ func (s *BlueprintService) Update(ctx context.Context, blueprint *Blueprint, eta
 
 	resp, err := s.client.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("%w", err)
+		return resp, fmt.Errorf("%w", err)
 	}
 
 	return resp, nil
@@ -412,14 +447,16 @@ func (s *BlueprintService) Delete(ctx context.Context, id string) (*Response, er
 	endpoint.WriteString(blueprintPath)
 	endpoint.WriteString("/" + id)
 
-	req, err := s.client.request(ctx, http.MethodDelete, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceBlueprint, "Delete", id)
+
+	req, err := s.client.request(opCtx, http.MethodDelete, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
 
 	resp, err := s.client.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("%w", err)
+		return resp, fmt.Errorf("%w", err)
 	}
 
 	return resp, nil
@@ -436,12 +473,65 @@ func (s *BlueprintService) ExportImage(
 	format string,
 	params *ImageExportParams,
 ) ([]byte, *Response, error) {
+	req, err := s.exportImageRequest(ctx, id, format, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return nil, resp, fmt.Errorf("%w", err)
+	}
+
+	return resp.Body, resp, nil
+}
+
+// ExportImageTo renders a blueprint for export in SVG, PNG, PDF or MxGraph
+// format and writes it to w as it is received, without holding the whole
+// export in memory. If an error occurs after the response started, w may
+// have received part of the body.
+//
+// [API reference].
+//
+// [API reference]: https://developers.cloudcraft.co/#8ad8ffa1-4a34-44e1-8795-4a851fc2fa58
+func (s *BlueprintService) ExportImageTo(
+	ctx context.Context,
+	w io.Writer,
+	id string,
+	format string,
+	params *ImageExportParams,
+) (*Response, error) {
+	if w == nil {
+		return nil, ErrNilWriter
+	}
+
+	req, err := s.exportImageRequest(ctx, id, format, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.stream(req, w)
+	if err != nil {
+		return resp, fmt.Errorf("%w", err)
+	}
+
+	return resp, nil
+}
+
+// exportImageRequest returns the request used by ExportImage and
+// ExportImageTo.
+func (s *BlueprintService) exportImageRequest(
+	ctx context.Context,
+	id string,
+	format string,
+	params *ImageExportParams,
+) (*http.Request, error) {
 	if ctx == nil {
-		return nil, nil, ErrNilContext
+		return nil, ErrNilContext
 	}
 
 	if id == "" {
-		return nil, nil, ErrMissingBlueprintID
+		return nil, ErrMissingBlueprintID
 	}
 
 	if format == "" {
@@ -469,41 +559,91 @@ func (s *BlueprintService) ExportImage(
 
 	u, err := url.Parse(endpoint.String())
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, fmt.Errorf("%w", err)
 	}
 
 	u.RawQuery = params.query().Encode()
 
-	req, err := s.client.request(ctx, http.MethodGet, u.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceBlueprint, "ExportImage", id)
+
+	req, err := s.client.request(opCtx, http.MethodGet, u.String(), http.NoBody)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return req, nil
+}
+
+// ExportBudget exports a blueprint's budget in CSV or XLSX format.
+//
+// [API reference].
+//
+// [API reference]: https://developers.cloudcraft.co/#4280d5b3-c9a1-423f-8074-0499447dd8d6
+func (s *BlueprintService) ExportBudget(
+	ctx context.Context,
+	id string,
+	format string,
+	params *BudgetExportParams,
+) ([]byte, *Response, error) {
+	req, err := s.exportBudgetRequest(ctx, id, format, params)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	resp, err := s.client.do(req)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, resp, fmt.Errorf("%w", err)
 	}
 
 	return resp.Body, resp, nil
 }
 
-// ExportBudget exports a blueprint's budget in CSV or XLSX format.
+// ExportBudgetTo exports a blueprint's budget in CSV or XLSX format and writes
+// it to w as it is received, without holding the whole export in memory. If
+// an error occurs after the response started, w may have received part of
+// the body.
 //
 // [API reference].
 //
 // [API reference]: https://developers.cloudcraft.co/#4280d5b3-c9a1-423f-8074-0499447dd8d6
-func (s *BlueprintService) ExportBudget(
+func (s *BlueprintService) ExportBudgetTo(
 	ctx context.Context,
+	w io.Writer,
 	id string,
 	format string,
 	params *BudgetExportParams,
-) ([]byte, *Response, error) {
+) (*Response, error) {
+	if w == nil {
+		return nil, ErrNilWriter
+	}
+
+	req, err := s.exportBudgetRequest(ctx, id, format, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.stream(req, w)
+	if err != nil {
+		return resp, fmt.Errorf("%w", err)
+	}
+
+	return resp, nil
+}
+
+// exportBudgetRequest returns the request used by ExportBudget and
+// ExportBudgetTo.
+func (s *BlueprintService) exportBudgetRequest(
+	ctx context.Context,
+	id string,
+	format string,
+	params *BudgetExportParams,
+) (*http.Request, error) {
 	if ctx == nil {
-		return nil, nil, ErrNilContext
+		return nil, ErrNilContext
 	}
 
 	if id == "" {
-		return nil, nil, ErrMissingBlueprintID
+		return nil, ErrMissingBlueprintID
 	}
 
 	if format == "" {
@@ -531,20 +671,60 @@ func (s *BlueprintService) ExportBudget(
 
 	u, err := url.Parse(endpoint.String())
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, fmt.Errorf("%w", err)
 	}
 
 	u.RawQuery = params.query().Encode()
 
-	req, err := s.client.request(ctx, http.MethodGet, u.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceBlueprint, "ExportBudget", id)
+
+	req, err := s.client.request(opCtx, http.MethodGet, u.String(), http.NoBody)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, fmt.Errorf("%w", err)
 	}
 
-	resp, err := s.client.do(req)
+	return req, nil
+}
+
+// findCreated looks for a blueprint created by a previous attempt of a Create
+// call started at the given time, by name, creator and creation time.
+func (s *BlueprintService) findCreated(
+	ctx context.Context,
+	blueprint *Blueprint,
+	start time.Time,
+) (*Blueprint, *Response, error) {
+	creator, err := s.client.creatorID(ctx)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, nil, err
 	}
 
-	return resp.Body, resp, nil
+	blueprints, resp, err := s.List(ctx)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	name := blueprintName(blueprint)
+
+	for _, candidate := range blueprints {
+		if candidate.Name == name && candidate.CreatorID == creator &&
+			createdSince(candidate.CreatedAt, start) {
+			return candidate, resp, nil
+		}
+	}
+
+	return nil, resp, nil
+}
+
+// blueprintName returns the name of a blueprint, which the API derives from
+// its data when not set explicitly.
+func blueprintName(blueprint *Blueprint) string {
+	if blueprint.Name != "" {
+		return blueprint.Name
+	}
+
+	if blueprint.Data != nil {
+		return blueprint.Data.Name
+	}
+
+	return ""
 }
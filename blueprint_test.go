@@ -529,7 +529,7 @@ func TestBlueprintService_Update(t *testing.T) {
 				t.Fatalf("Blueprint.Update() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			if !tt.wantErr && !reflect.DeepEqual(xtesting.NormalizeResponse(got), xtesting.NormalizeResponse(tt.want)) {
 				t.Fatalf("Blueprint.Update() = %v, want %v", got, tt.want)
 			}
 		})
@@ -616,7 +616,7 @@ func TestBlueprintService_Delete(t *testing.T) {
 				t.Fatalf("Blueprint.Delete() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			if !tt.wantErr && !reflect.DeepEqual(xtesting.NormalizeResponse(got), xtesting.NormalizeResponse(tt.want)) {
 				t.Fatalf("Blueprint.Delete() = %v, want %v", got, tt.want)
 			}
 		})
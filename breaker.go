@@ -0,0 +1,245 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/breaker"
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+)
+
+const (
+	// ErrCircuitOpen is returned when a call is rejected without being sent
+	// because the circuit breaker of the client is open.
+	ErrCircuitOpen xerrors.Error = "circuit breaker is open"
+
+	// ErrInvalidCircuitBreaker is returned when a Config is created with an
+	// invalid CircuitBreakerConfig.
+	ErrInvalidCircuitBreaker xerrors.Error = "invalid circuit breaker config"
+)
+
+const (
+	// DefaultCircuitFailureRate is the default failure rate at which the
+	// circuit breaker opens.
+	DefaultCircuitFailureRate float64 = 0.5
+
+	// DefaultCircuitWindow is the default number of recent attempts over which
+	// the failure rate is computed.
+	DefaultCircuitWindow int = 20
+
+	// DefaultCircuitMinRequests is the default minimum number of attempts in
+	// the window before the circuit breaker can open.
+	DefaultCircuitMinRequests int = 10
+
+	// DefaultCircuitCooldown is the default duration for which the circuit
+	// breaker stays open.
+	DefaultCircuitCooldown time.Duration = 30 * time.Second
+
+	// DefaultCircuitProbes is the default number of probe attempts let through
+	// when the circuit breaker is half-open.
+	DefaultCircuitProbes int = 1
+)
+
+// CircuitState is the state of the circuit breaker of a client.
+type CircuitState int
+
+const (
+	// CircuitClosed is the state of a circuit breaker that lets calls through.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen is the state of a circuit breaker that rejects calls with
+	// ErrCircuitOpen until its cool-down period is over.
+	CircuitOpen
+
+	// CircuitHalfOpen is the state of a circuit breaker that lets a few probe
+	// attempts through to tell whether the Cloudcraft API recovered.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitBreakerConfig configures the circuit breaker of a client.
+//
+// The circuit breaker watches the outcome of the recent attempts sent to the
+// Cloudcraft API. Connection errors and server errors (5xx) count as failures.
+// When the rate of failures reaches FailureRate, the breaker opens: calls and
+// retries fail fast with ErrCircuitOpen instead of being sent. After the
+// Cooldown, the breaker is half-open and lets Probes attempts through. If they
+// all succeed it closes, otherwise it opens again.
+type CircuitBreakerConfig struct {
+	// OnStateChange is called after every change of state of the circuit
+	// breaker, for example to raise an alert when it opens. It must not
+	// block.
+	//
+	// This field is optional.
+	OnStateChange func(from, to CircuitState)
+
+	// FailureRate is the rate of failed attempts, between 0 and 1, at which
+	// the circuit breaker opens.
+	//
+	// If not set, the default value is 0.5.
+	//
+	// This field is optional.
+	FailureRate float64
+
+	// Window is the number of recent attempts over which the failure rate is
+	// computed.
+	//
+	// If not set, the default value is 20.
+	//
+	// This field is optional.
+	Window int
+
+	// MinRequests is the minimum number of attempts in the window before the
+	// circuit breaker can open, so that a few failures do not open it.
+	//
+	// If not set, the default value is 10.
+	//
+	// This field is optional.
+	MinRequests int
+
+	// Cooldown is how long the circuit breaker stays open before letting
+	// probes through.
+	//
+	// If not set, the default value is 30 seconds.
+	//
+	// This field is optional.
+	Cooldown time.Duration
+
+	// Probes is the number of attempts let through when the circuit breaker
+	// is half-open, all of which must succeed for it to close.
+	//
+	// If not set, the default value is 1.
+	//
+	// This field is optional.
+	Probes int
+}
+
+// Validate checks that the CircuitBreakerConfig is valid.
+func (b *CircuitBreakerConfig) Validate() error {
+	if b.FailureRate < 0 || b.FailureRate > 1 {
+		return fmt.Errorf("%w: failure rate must be between 0 and 1", ErrInvalidCircuitBreaker)
+	}
+
+	if b.Window < 0 || b.MinRequests < 0 || b.Probes < 0 || b.Cooldown < 0 {
+		return fmt.Errorf("%w: values cannot be negative", ErrInvalidCircuitBreaker)
+	}
+
+	return nil
+}
+
+// newCircuitBreaker returns the circuit breaker of the client given a Config,
+// or nil if it is disabled.
+func newCircuitBreaker(cfg *Config) *breaker.Breaker {
+	if cfg.CircuitBreaker == nil {
+		return nil
+	}
+
+	var (
+		settings = cfg.CircuitBreaker
+		logger   = cfg.Logger
+	)
+
+	// The values of breaker.State and CircuitState are the same.
+	bcfg := breaker.Config{
+		OnStateChange: func(from, to breaker.State) {
+			if logger != nil {
+				logger.LogAttrs(context.Background(), slog.LevelWarn, "cloudcraft: circuit breaker state changed",
+					slog.String("from", CircuitState(from).String()),
+					slog.String("to", CircuitState(to).String()),
+				)
+			}
+
+			if settings.OnStateChange != nil {
+				settings.OnStateChange(CircuitState(from), CircuitState(to))
+			}
+		},
+		FailureRate: settings.FailureRate,
+		Window:      settings.Window,
+		MinRequests: settings.MinRequests,
+		Cooldown:    settings.Cooldown,
+		Probes:      settings.Probes,
+	}
+
+	if bcfg.FailureRate == 0 {
+		bcfg.FailureRate = DefaultCircuitFailureRate
+	}
+
+	if bcfg.Window == 0 {
+		bcfg.Window = DefaultCircuitWindow
+	}
+
+	if bcfg.MinRequests == 0 {
+		bcfg.MinRequests = DefaultCircuitMinRequests
+	}
+
+	if bcfg.Cooldown == 0 {
+		bcfg.Cooldown = DefaultCircuitCooldown
+	}
+
+	if bcfg.Probes == 0 {
+		bcfg.Probes = DefaultCircuitProbes
+	}
+
+	return breaker.New(bcfg)
+}
+
+// CircuitState returns the state of the circuit breaker of the client. It
+// returns CircuitClosed if Config.CircuitBreaker is not set.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+
+	return CircuitState(c.breaker.State())
+}
+
+// allowAttempt asks the circuit breaker whether an attempt can be sent. It
+// returns the generation to pass to recordAttempt.
+func (c *Client) allowAttempt() (uint64, error) {
+	if c.breaker == nil {
+		return 0, nil
+	}
+
+	generation, ok := c.breaker.Allow()
+	if !ok {
+		return 0, ErrCircuitOpen
+	}
+
+	return generation, nil
+}
+
+// recordAttempt reports the outcome of an attempt to the circuit breaker.
+// Attempts interrupted by the context of their caller are ignored.
+func (c *Client) recordAttempt(req *http.Request, generation uint64, resp *http.Response, err error) {
+	if c.breaker == nil {
+		return
+	}
+
+	if req.Context().Err() != nil {
+		c.breaker.Release(generation)
+
+		return
+	}
+
+	c.breaker.Record(generation, err == nil && resp.StatusCode < http.StatusInternalServerError)
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+)
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		down  atomic.Bool
+		calls atomic.Int32
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	const cooldown = 100 * time.Millisecond
+
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+
+	cfg := &cloudcraft.Config{
+		Endpoint: ts.URL,
+		Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		CircuitBreaker: &cloudcraft.CircuitBreakerConfig{
+			OnStateChange: func(from, to cloudcraft.CircuitState) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				transitions = append(transitions, from.String()+" -> "+to.String())
+			},
+			Window:      2,
+			MinRequests: 2,
+			Cooldown:    cooldown,
+		},
+	}
+
+	fastRetries(cfg)
+
+	client, err := cloudcraft.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+
+	down.Store(true)
+
+	// The breaker opens after the second attempt, which cuts the retries
+	// short.
+	if _, _, err = client.User.Me(ctx); !errors.Is(err, cloudcraft.ErrCircuitOpen) {
+		t.Fatalf("User.Me() error = %v, want %v", err, cloudcraft.ErrCircuitOpen)
+	}
+
+	if got := client.CircuitState(); got != cloudcraft.CircuitOpen {
+		t.Fatalf("CircuitState() = %v, want %v", got, cloudcraft.CircuitOpen)
+	}
+
+	// Calls fail fast while the breaker is open.
+	if _, _, err = client.User.Me(ctx); !errors.Is(err, cloudcraft.ErrCircuitOpen) {
+		t.Fatalf("User.Me() error = %v, want %v", err, cloudcraft.ErrCircuitOpen)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls to the API = %d, want 2", got)
+	}
+
+	// Once the cool-down is over, a successful probe closes the breaker.
+	down.Store(false)
+	time.Sleep(cooldown)
+
+	if got := client.CircuitState(); got != cloudcraft.CircuitHalfOpen {
+		t.Fatalf("CircuitState() = %v, want %v", got, cloudcraft.CircuitHalfOpen)
+	}
+
+	if _, _, err = client.User.Me(ctx); err != nil {
+		t.Fatalf("User.Me() error = %v", err)
+	}
+
+	if got := client.CircuitState(); got != cloudcraft.CircuitClosed {
+		t.Fatalf("CircuitState() = %v, want %v", got, cloudcraft.CircuitClosed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	want := []string{"closed -> open", "open -> half-open", "half-open -> closed"}
+
+	if !reflect.DeepEqual(transitions, want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestCircuitBreakerConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		give cloudcraft.CircuitBreakerConfig
+		want error
+	}{
+		{
+			name: "Defaults",
+			give: cloudcraft.CircuitBreakerConfig{},
+			want: nil,
+		},
+		{
+			name: "Valid settings",
+			give: cloudcraft.CircuitBreakerConfig{FailureRate: 0.25, Window: 50, MinRequests: 20, Cooldown: time.Minute, Probes: 3},
+			want: nil,
+		},
+		{
+			name: "Failure rate above 1",
+			give: cloudcraft.CircuitBreakerConfig{FailureRate: 1.5},
+			want: cloudcraft.ErrInvalidCircuitBreaker,
+		},
+		{
+			name: "Negative cool-down",
+			give: cloudcraft.CircuitBreakerConfig{Cooldown: -time.Second},
+			want: cloudcraft.ErrInvalidCircuitBreaker,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.give.Validate(); !errors.Is(err, tt.want) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
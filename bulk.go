@@ -0,0 +1,304 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const (
+	// DefaultBulkInitialConcurrency is the default number of items processed
+	// concurrently when a bulk execution starts.
+	DefaultBulkInitialConcurrency int = 4
+
+	// DefaultBulkMinConcurrency is the default minimum number of items
+	// processed concurrently by a bulk execution.
+	DefaultBulkMinConcurrency int = 1
+
+	// DefaultBulkMaxConcurrency is the default maximum number of items
+	// processed concurrently by a bulk execution.
+	DefaultBulkMaxConcurrency int = 32
+
+	// DefaultBulkDecreaseFactor is the default factor applied to the
+	// concurrency of a bulk execution when the API signals it is overloaded.
+	DefaultBulkDecreaseFactor float64 = 0.5
+)
+
+// attemptObserverContextKey is the context key for attempt observers.
+type attemptObserverContextKey struct{}
+
+// attemptObserver is notified of the outcome of every attempt made by
+// Client.do for a request carrying it in its context.
+type attemptObserver func(resp *http.Response, err error)
+
+// BulkOptions configures how Bulk adjusts the number of items it processes
+// concurrently.
+//
+// Concurrency follows an additive-increase/multiplicative-decrease (AIMD)
+// algorithm: it grows by about one for every window of items that complete
+// without the API responding with 429 or 5xx to any of their attempts, and is
+// multiplied by DecreaseFactor when it does.
+type BulkOptions struct {
+	// InitialConcurrency is the number of items processed concurrently when
+	// the execution starts.
+	//
+	// If not set, the default value is 4.
+	InitialConcurrency int
+
+	// MinConcurrency is the minimum number of items processed concurrently.
+	//
+	// If not set, the default value is 1.
+	MinConcurrency int
+
+	// MaxConcurrency is the maximum number of items processed concurrently.
+	//
+	// If not set, the default value is 32.
+	MaxConcurrency int
+
+	// DecreaseFactor is the factor, between 0 and 1, applied to the
+	// concurrency when the API responds with 429 or 5xx.
+	//
+	// If not set, the default value is 0.5.
+	DecreaseFactor float64
+}
+
+// BulkResult holds the outcome of processing one item in a bulk execution.
+type BulkResult[T, R any] struct {
+	// Item is the item that was processed.
+	Item T
+
+	// Value is the value returned for the item.
+	Value R
+
+	// Err is the error returned for the item, if any. Items that were not
+	// processed because the context was canceled hold the context's error.
+	Err error
+
+	// Index is the position of the item in the input slice.
+	Index int
+}
+
+// Bulk calls fn for every item concurrently and sends the outcome of each call
+// to the returned channel, in completion order. The channel is closed once all
+// items are processed.
+//
+// The number of concurrent calls adapts to the load of the Cloudcraft API, as
+// described in BulkOptions, based on the responses to requests made with the
+// context passed to fn. If opts is nil, default options are used.
+//
+// When ctx is canceled, items that have not started are reported with the
+// context's error. The channel is buffered to hold every result, so Bulk never
+// blocks on a slow reader.
+func Bulk[T, R any](
+	ctx context.Context,
+	items []T,
+	fn func(ctx context.Context, item T) (R, error),
+	opts *BulkOptions,
+) <-chan BulkResult[T, R] {
+	results := make(chan BulkResult[T, R], len(items))
+	controller := newAIMDController(opts)
+
+	go func() {
+		var wg sync.WaitGroup
+
+		defer close(results)
+		defer wg.Wait()
+
+		for i, item := range items {
+			epoch, err := controller.acquire(ctx)
+			if err != nil {
+				results <- BulkResult[T, R]{Item: item, Err: err, Index: i}
+
+				continue
+			}
+
+			wg.Add(1)
+
+			go func(i int, item T) {
+				defer wg.Done()
+
+				var (
+					mu        sync.Mutex
+					throttled bool
+				)
+
+				itemCtx := context.WithValue(ctx, attemptObserverContextKey{}, attemptObserver(
+					func(resp *http.Response, _ error) {
+						if resp == nil || !isOverloaded(resp.StatusCode) {
+							return
+						}
+
+						mu.Lock()
+						throttled = true
+						mu.Unlock()
+
+						controller.decrease(epoch)
+					},
+				))
+
+				value, err := fn(itemCtx, item)
+
+				mu.Lock()
+				success := !throttled
+				mu.Unlock()
+
+				controller.release(success)
+
+				results <- BulkResult[T, R]{Item: item, Value: value, Err: err, Index: i}
+			}(i, item)
+		}
+	}()
+
+	return results
+}
+
+// observeAttempt notifies the attempt observer carried by ctx, if any, of the
+// outcome of an attempt.
+func observeAttempt(ctx context.Context, resp *http.Response, err error) {
+	if observe, ok := ctx.Value(attemptObserverContextKey{}).(attemptObserver); ok {
+		observe(resp, err)
+	}
+}
+
+// isOverloaded reports whether a status code signals that the Cloudcraft API
+// is overloaded.
+func isOverloaded(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// aimdController limits the number of concurrent operations with an
+// additive-increase/multiplicative-decrease algorithm.
+type aimdController struct {
+	// changed is closed and replaced whenever an operation completes, to wake
+	// up callers waiting in acquire.
+	changed chan struct{}
+
+	// limit is the current number of operations allowed to run concurrently.
+	limit float64
+
+	// min and max bound limit.
+	min, max float64
+
+	// factor is applied to limit when the API signals it is overloaded.
+	factor float64
+
+	// inFlight is the number of operations currently running.
+	inFlight int
+
+	// epoch is incremented on every decrease, so that the operations that
+	// were already running when the limit decreased do not decrease it again.
+	epoch uint64
+
+	mu sync.Mutex
+}
+
+// newAIMDController returns a new aimdController given BulkOptions, using
+// default values for unset options.
+func newAIMDController(opts *BulkOptions) *aimdController {
+	var o BulkOptions
+
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.MinConcurrency <= 0 {
+		o.MinConcurrency = DefaultBulkMinConcurrency
+	}
+
+	if o.MaxConcurrency <= 0 {
+		o.MaxConcurrency = DefaultBulkMaxConcurrency
+	}
+
+	o.MaxConcurrency = max(o.MaxConcurrency, o.MinConcurrency)
+
+	if o.InitialConcurrency <= 0 {
+		o.InitialConcurrency = DefaultBulkInitialConcurrency
+	}
+
+	if o.DecreaseFactor <= 0 || o.DecreaseFactor >= 1 {
+		o.DecreaseFactor = DefaultBulkDecreaseFactor
+	}
+
+	return &aimdController{
+		changed: make(chan struct{}),
+		limit:   float64(min(max(o.InitialConcurrency, o.MinConcurrency), o.MaxConcurrency)),
+		min:     float64(o.MinConcurrency),
+		max:     float64(o.MaxConcurrency),
+		factor:  o.DecreaseFactor,
+	}
+}
+
+// acquire blocks until an operation is allowed to start or the context is
+// canceled. It returns the epoch the operation starts in.
+func (c *aimdController) acquire(ctx context.Context) (uint64, error) {
+	for {
+		c.mu.Lock()
+
+		if c.inFlight < int(c.limit) {
+			c.inFlight++
+			epoch := c.epoch
+			c.mu.Unlock()
+
+			return epoch, nil
+		}
+
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("%w", ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// release marks an operation as complete. Successful operations increase the
+// limit additively.
+func (c *aimdController) release(success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight--
+
+	if success {
+		c.limit = min(c.max, c.limit+1/c.limit)
+	}
+
+	c.notify()
+}
+
+// decrease multiplies the limit by the decrease factor, unless it already
+// decreased since the operation that observed the overload started.
+func (c *aimdController) decrease(epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if epoch != c.epoch {
+		return
+	}
+
+	c.epoch++
+	c.limit = max(c.min, c.limit*c.factor)
+}
+
+// notify wakes up callers waiting in acquire. It must be called with c.mu
+// held.
+func (c *aimdController) notify() {
+	close(c.changed)
+
+	c.changed = make(chan struct{})
+}
+
+// concurrency returns the current concurrency limit.
+func (c *aimdController) concurrency() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return int(c.limit)
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAIMDController(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = context.Background()
+		controller = newAIMDController(&BulkOptions{
+			InitialConcurrency: 4,
+			MinConcurrency:     1,
+			MaxConcurrency:     5,
+		})
+	)
+
+	epochs := make([]uint64, 0, 4)
+
+	for i := 0; i < 4; i++ {
+		epoch, err := controller.acquire(ctx)
+		if err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+
+		epochs = append(epochs, epoch)
+	}
+
+	// The limit is reached, so the next acquisition blocks until the context
+	// expires.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := controller.acquire(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Several operations started in the same epoch observe an overload, but
+	// the limit is only halved once.
+	controller.decrease(epochs[0])
+	controller.decrease(epochs[1])
+
+	if got := controller.concurrency(); got != 2 {
+		t.Fatalf("concurrency() after decrease = %d, want 2", got)
+	}
+
+	for i := 0; i < 4; i++ {
+		controller.release(false)
+	}
+
+	// Successful operations grow the limit by about one per window.
+	for i := 0; i < 10; i++ {
+		if _, err := controller.acquire(ctx); err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+
+		controller.release(true)
+	}
+
+	if got := controller.concurrency(); got <= 2 || got > 5 {
+		t.Fatalf("concurrency() after successes = %d, want between 3 and 5", got)
+	}
+
+	for i := 0; i < 100; i++ {
+		controller.decrease(controller.epoch)
+	}
+
+	if got := controller.concurrency(); got != 1 {
+		t.Fatalf("concurrency() after repeated decreases = %d, want 1", got)
+	}
+}
+
+func TestNewAIMDController_Defaults(t *testing.T) {
+	t.Parallel()
+
+	controller := newAIMDController(nil)
+
+	if got := controller.concurrency(); got != DefaultBulkInitialConcurrency {
+		t.Errorf("concurrency() = %d, want %d", got, DefaultBulkInitialConcurrency)
+	}
+
+	if controller.min != float64(DefaultBulkMinConcurrency) || controller.max != float64(DefaultBulkMaxConcurrency) {
+		t.Errorf("bounds = [%v, %v], want [%d, %d]",
+			controller.min, controller.max, DefaultBulkMinConcurrency, DefaultBulkMaxConcurrency)
+	}
+
+	if controller.factor != DefaultBulkDecreaseFactor {
+		t.Errorf("factor = %v, want %v", controller.factor, DefaultBulkDecreaseFactor)
+	}
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestBulk(t *testing.T) {
+	t.Parallel()
+
+	var (
+		validTestData = xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "get-valid.json"))
+		throttled     sync.Map
+		inFlight      atomic.Int32
+		maxInFlight   atomic.Int32
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+
+		// Throttle the first attempt for every third item to exercise the
+		// decrease path. Retries always go through.
+		id := path.Base(r.URL.Path)
+		if index, err := strconv.Atoi(strings.TrimPrefix(id, "item-")); err == nil && index%3 == 0 {
+			if _, loaded := throttled.LoadOrStore(id, true); !loaded {
+				w.WriteHeader(http.StatusTooManyRequests)
+
+				return
+			}
+		}
+
+		if id == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		w.Write(validTestData)
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, fastRetries)
+
+	ids := make([]string, 0, 40)
+
+	for i := 0; i < 39; i++ {
+		ids = append(ids, "item-"+strconv.Itoa(i))
+	}
+
+	ids = append(ids, "missing")
+
+	results := cloudcraft.Bulk(context.Background(), ids,
+		func(ctx context.Context, id string) (*cloudcraft.Blueprint, error) {
+			blueprint, _, err := client.Blueprint.Get(ctx, id)
+
+			return blueprint, err
+		},
+		&cloudcraft.BulkOptions{InitialConcurrency: 2, MaxConcurrency: 8},
+	)
+
+	seen := make(map[int]bool, len(ids))
+
+	for result := range results {
+		if seen[result.Index] {
+			t.Fatalf("duplicate result for item %d", result.Index)
+		}
+
+		seen[result.Index] = true
+
+		if result.Item != ids[result.Index] {
+			t.Errorf("result.Item = %q, want %q", result.Item, ids[result.Index])
+		}
+
+		if result.Item == "missing" {
+			if !cloudcraft.IsNotFound(result.Err) {
+				t.Errorf("result.Err = %v, want a not found error", result.Err)
+			}
+
+			continue
+		}
+
+		if result.Err != nil || result.Value == nil {
+			t.Errorf("result = %+v, want a blueprint", result)
+		}
+	}
+
+	if len(seen) != len(ids) {
+		t.Fatalf("got %d results, want %d", len(seen), len(ids))
+	}
+
+	if got := maxInFlight.Load(); got > 8 {
+		t.Errorf("max in-flight requests = %d, want at most 8", got)
+	}
+}
+
+func TestBulk_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	items := []int{1, 2, 3, 4, 5}
+
+	results := cloudcraft.Bulk(ctx, items,
+		func(ctx context.Context, item int) (int, error) {
+			if item == 1 {
+				cancel()
+			}
+
+			<-ctx.Done()
+
+			return 0, ctx.Err()
+		},
+		&cloudcraft.BulkOptions{InitialConcurrency: 1, MaxConcurrency: 1},
+	)
+
+	var count int
+
+	for result := range results {
+		count++
+
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("result.Err for item %d = %v, want %v", result.Item, result.Err, context.Canceled)
+		}
+	}
+
+	if count != len(items) {
+		t.Fatalf("got %d results, want %d", count, len(items))
+	}
+}
@@ -0,0 +1,390 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/xhttp"
+)
+
+// DefaultCacheEntries is the default maximum number of responses held by a
+// MemoryCache or a DiskCache.
+const DefaultCacheEntries int = 256
+
+const (
+	// diskCacheTempPrefix starts the names of the temporary files of the
+	// entries being written to a DiskCache.
+	diskCacheTempPrefix string = ".entry-"
+
+	// diskCacheTempPattern is the pattern of the names of the temporary files
+	// of a DiskCache.
+	diskCacheTempPattern string = diskCacheTempPrefix + "*"
+)
+
+// Cache stores the responses to GET requests so that they can be revalidated
+// with conditional requests. The client sends the ETag and Last-Modified
+// values of a cached response in the If-None-Match and If-Modified-Since
+// headers, and serves the cached body when the API replies 304 Not Modified.
+//
+// Cached responses are always revalidated, so a Cache never serves stale data.
+// Keys identify a request and the credentials it is made with; they hold no
+// secret.
+//
+// Implementations must be safe for concurrent use. They are best-effort: a
+// failure to store an entry only means that the next request is not
+// conditional.
+type Cache interface {
+	// Get returns the entry stored under key, if any.
+	Get(key string) (*CacheEntry, bool)
+
+	// Set stores entry under key, replacing any previous entry. The entry is
+	// owned by the cache once Set is called.
+	Set(key string, entry *CacheEntry)
+}
+
+// CacheEntry is a response stored in a Cache.
+type CacheEntry struct {
+	// Header contains the response headers.
+	Header http.Header `json:"header"`
+
+	// ETag is the entity tag of the response, sent back in the If-None-Match
+	// header to revalidate it.
+	ETag string `json:"etag,omitempty"`
+
+	// LastModified is the last modification date of the response, sent back
+	// in the If-Modified-Since header to revalidate it.
+	LastModified string `json:"lastModified,omitempty"`
+
+	// Body contains the response body.
+	Body []byte `json:"body"`
+
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+}
+
+var (
+	_ Cache = (*MemoryCache)(nil)
+	_ Cache = (*DiskCache)(nil)
+)
+
+// MemoryCache is a Cache that holds a bounded number of responses in memory,
+// evicting the least recently used ones first.
+type MemoryCache struct {
+	entries    map[string]*list.Element
+	order      *list.List
+	maxEntries int
+	mu         sync.Mutex
+}
+
+// memoryCacheItem is an element of the recency list of a MemoryCache.
+type memoryCacheItem struct {
+	entry *CacheEntry
+	key   string
+}
+
+// NewMemoryCache returns a new MemoryCache holding up to maxEntries responses.
+// If maxEntries is zero or negative, DefaultCacheEntries is used.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+
+	return &MemoryCache{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get implements the Cache interface.
+func (m *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	m.order.MoveToFront(element)
+
+	return element.Value.(*memoryCacheItem).entry, true //nolint:forcetypeassert // The list only holds items.
+}
+
+// Set implements the Cache interface.
+func (m *MemoryCache) Set(key string, entry *CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryCacheItem).entry = entry //nolint:forcetypeassert // The list only holds items.
+		m.order.MoveToFront(element)
+
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryCacheItem{entry: entry, key: key})
+
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheItem).key) //nolint:forcetypeassert // The list only holds items.
+	}
+}
+
+// Len returns the number of responses held by the cache.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
+
+// DiskCache is a Cache that stores a bounded number of responses as files in a
+// directory, so that they survive restarts and can be shared by processes. The
+// least recently used responses are evicted first.
+type DiskCache struct {
+	dir        string
+	maxEntries int
+	mu         sync.Mutex
+}
+
+// NewDiskCache returns a new DiskCache storing up to maxEntries responses in
+// dir, which is created if it does not exist. If maxEntries is zero or
+// negative, DefaultCacheEntries is used.
+func NewDiskCache(dir string, maxEntries int) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+
+	return &DiskCache{dir: dir, maxEntries: maxEntries}, nil
+}
+
+// Get implements the Cache interface. Unreadable entries are treated as
+// missing.
+func (d *DiskCache) Get(key string) (*CacheEntry, bool) {
+	path := d.path(key)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	// The modification time of an entry records its last use, for eviction.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return &entry, true
+}
+
+// Set implements the Cache interface. The entry is written to a temporary file
+// first, so that concurrent readers never see a partial entry.
+func (d *DiskCache) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(d.dir, diskCacheTempPattern)
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), d.path(key))
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return
+	}
+
+	d.evict()
+}
+
+// Len returns the number of responses held by the cache.
+func (d *DiskCache) Len() int {
+	entries, _ := d.entries()
+
+	return len(entries)
+}
+
+// evict removes the least recently used entries beyond the maximum number of
+// entries of the cache. Entries removed concurrently by another process are
+// ignored.
+func (d *DiskCache) evict() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entries, err := d.entries()
+	if err != nil || len(entries) <= d.maxEntries {
+		return
+	}
+
+	slices.SortFunc(entries, func(a, b fs.FileInfo) int {
+		return a.ModTime().Compare(b.ModTime())
+	})
+
+	for _, entry := range entries[:len(entries)-d.maxEntries] {
+		_ = os.Remove(filepath.Join(d.dir, entry.Name()))
+	}
+}
+
+// entries returns the files of the entries stored in the cache, leaving out
+// the temporary files of entries being written.
+func (d *DiskCache) entries() ([]fs.FileInfo, error) {
+	dirEntries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	entries := make([]fs.FileInfo, 0, len(dirEntries))
+
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.Type().IsRegular() || strings.HasPrefix(dirEntry.Name(), diskCacheTempPrefix) {
+			continue
+		}
+
+		// Entries removed since the directory was read are skipped.
+		if info, err := dirEntry.Info(); err == nil {
+			entries = append(entries, info)
+		}
+	}
+
+	return entries, nil
+}
+
+// path returns the path of the file storing the entry under key.
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+// requestKey returns a key identifying req and the credentials it is made
+// with, without holding them.
+func requestKey(req *http.Request) string {
+	credentials := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+
+	return req.Method + " " + req.URL.String() + " " + hex.EncodeToString(credentials[:8])
+}
+
+// revalidate looks up the cached response to req, and returns a conditional
+// copy of req if there is one. It also returns the cached entry, if any, and
+// the key to store the response under, which is empty if the response is not
+// cacheable.
+func (c *Client) revalidate(req *http.Request, w io.Writer) (*http.Request, *CacheEntry, string) {
+	if c.cache == nil || w != nil || req.Method != http.MethodGet {
+		return req, nil, ""
+	}
+
+	key := requestKey(req)
+
+	entry, ok := c.cache.Get(key)
+	if !ok {
+		return req, nil, key
+	}
+
+	req = req.Clone(req.Context())
+
+	if entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	if entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	return req, entry, key
+}
+
+// store caches response under key if it can be revalidated later.
+func (c *Client) store(key string, response *Response) {
+	var (
+		etag         = response.Header.Get("ETag")
+		lastModified = response.Header.Get("Last-Modified")
+	)
+
+	if key == "" || response.Status != http.StatusOK || (etag == "" && lastModified == "") {
+		return
+	}
+
+	c.cache.Set(key, &CacheEntry{
+		Header:       response.Header.Clone(),
+		ETag:         etag,
+		LastModified: lastModified,
+		Body:         append([]byte(nil), response.Body...),
+		Status:       response.Status,
+	})
+}
+
+// fromCache returns the Response to a request answered with 304 Not Modified,
+// built from the cached entry and updated with the headers of resp.
+func fromCache(resp *http.Response, entry *CacheEntry) *Response {
+	if err := xhttp.DrainResponseBody(resp); err != nil {
+		_ = resp.Body.Close()
+	}
+
+	header := entry.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	for key, values := range resp.Header {
+		if key != "Content-Length" {
+			header[key] = values
+		}
+	}
+
+	return &Response{
+		Header: header,
+		Body:   append([]byte(nil), entry.Body...),
+		BodyStats: BodyStats{
+			Size: int64(len(entry.Body)),
+		},
+		Status: entry.Status,
+		Cached: true,
+	}
+}
+
+// notModified reports whether resp is a 304 Not Modified response to req, a
+// conditional request.
+func notModified(req *http.Request, resp *http.Response) bool {
+	if resp.StatusCode != http.StatusNotModified {
+		return false
+	}
+
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
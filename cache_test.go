@@ -0,0 +1,276 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestClient_Cache(t *testing.T) {
+	t.Parallel()
+
+	var (
+		validTestData = xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "get-valid.json"))
+		etag          atomic.Value
+		fullResponses atomic.Int32
+		notModified   atomic.Int32
+	)
+
+	etag.Store(`"v1"`)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current, _ := etag.Load().(string)
+
+		w.Header().Set("ETag", current)
+
+		if r.Header.Get("If-None-Match") == current {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		fullResponses.Add(1)
+		w.WriteHeader(http.StatusOK)
+		w.Write(validTestData)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name  string
+		cache func(t *testing.T) cloudcraft.Cache
+	}{
+		{
+			name: "MemoryCache",
+			cache: func(*testing.T) cloudcraft.Cache {
+				return cloudcraft.NewMemoryCache(0)
+			},
+		},
+		{
+			name: "DiskCache",
+			cache: func(t *testing.T) cloudcraft.Cache {
+				cache, err := cloudcraft.NewDiskCache(filepath.Join(t.TempDir(), "cache"), 0)
+				if err != nil {
+					t.Fatalf("NewDiskCache() error = %v", err)
+				}
+
+				return cache
+			},
+		},
+	}
+
+	for _, tt := range tests { //nolint:paralleltest // The subtests share the counters of the server.
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			etag.Store(`"v1"`)
+			fullResponses.Store(0)
+			notModified.Store(0)
+
+			metrics := cloudcraft.NewInMemoryMetrics()
+
+			client, err := cloudcraft.NewClient(&cloudcraft.Config{
+				Endpoint: ts.URL,
+				Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				Cache:    tt.cache(t),
+				Metrics:  metrics,
+			})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			ctx := context.Background()
+
+			first, firstResp, err := client.Blueprint.Get(ctx, "blueprint-id")
+			if err != nil {
+				t.Fatalf("Blueprint.Get() error = %v", err)
+			}
+
+			if firstResp.Cached {
+				t.Fatal("first response is cached, want it downloaded")
+			}
+
+			second, secondResp, err := client.Blueprint.Get(ctx, "blueprint-id")
+			if err != nil {
+				t.Fatalf("Blueprint.Get() error = %v", err)
+			}
+
+			if !secondResp.Cached || secondResp.Status != http.StatusOK {
+				t.Fatalf("second response = cached %v, status %d, want a cached 200", secondResp.Cached, secondResp.Status)
+			}
+
+			if !bytes.Equal(firstResp.Body, secondResp.Body) || first.ID != second.ID {
+				t.Fatal("cached response differs from the original one")
+			}
+
+			// The cached body was not received again.
+			if op, _ := metrics.Operation("Blueprint", "Get"); op.BytesIn != int64(len(firstResp.Body)) {
+				t.Fatalf("bytes received = %d, want %d", op.BytesIn, len(firstResp.Body))
+			}
+
+			// A changed blueprint is downloaded again.
+			etag.Store(`"v2"`)
+
+			_, thirdResp, err := client.Blueprint.Get(ctx, "blueprint-id")
+			if err != nil {
+				t.Fatalf("Blueprint.Get() error = %v", err)
+			}
+
+			if thirdResp.Cached {
+				t.Fatal("response to a changed blueprint is cached, want it downloaded")
+			}
+
+			if fullResponses.Load() != 2 || notModified.Load() != 1 {
+				t.Fatalf("server sent %d full and %d not modified responses, want 2 and 1", fullResponses.Load(), notModified.Load())
+			}
+		})
+	}
+}
+
+func TestClient_Cache_Credentials(t *testing.T) {
+	t.Parallel()
+
+	var conditional atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional.Add(1)
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	cache := cloudcraft.NewMemoryCache(0)
+
+	for _, key := range []string{
+		"not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		"not-a-real-key-qNx7ZzX0ibwKcqYSdFBJeMm5Ymtd=",
+	} {
+		client, err := cloudcraft.NewClient(&cloudcraft.Config{
+			Endpoint: ts.URL,
+			Key:      key,
+			Cache:    cache,
+		})
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+
+		if _, _, err = client.User.Me(context.Background()); err != nil {
+			t.Fatalf("User.Me() error = %v", err)
+		}
+	}
+
+	// Responses fetched with other credentials are never reused.
+	if conditional.Load() != 0 || cache.Len() != 2 {
+		t.Fatalf("conditional requests = %d, cached entries = %d, want 0 and 2", conditional.Load(), cache.Len())
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+
+	cache := cloudcraft.NewMemoryCache(2)
+
+	cache.Set("a", &cloudcraft.CacheEntry{ETag: "a"})
+	cache.Set("b", &cloudcraft.CacheEntry{ETag: "b"})
+
+	// Reading "a" makes "b" the least recently used entry.
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Get(a) = false, want true")
+	}
+
+	cache.Set("c", &cloudcraft.CacheEntry{ETag: "c"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("Get(b) = true, want it evicted")
+	}
+
+	for _, key := range []string{"a", "c"} {
+		if entry, ok := cache.Get(key); !ok || entry.ETag != key {
+			t.Fatalf("Get(%s) = %+v, %v, want the stored entry", key, entry, ok)
+		}
+	}
+
+	if cache.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", cache.Len())
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	cache, err := cloudcraft.NewDiskCache(dir, 2)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	want := &cloudcraft.CacheEntry{
+		Header: http.Header{"Content-Type": {"application/json"}},
+		ETag:   `"v1"`,
+		Body:   []byte(`{}`),
+		Status: http.StatusOK,
+	}
+
+	cache.Set("key", want)
+
+	// A new cache over the same directory reads the stored entries.
+	reopened, err := cloudcraft.NewDiskCache(dir, 2)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	got, ok := reopened.Get("key")
+	if !ok {
+		t.Fatal("Get() = false, want true")
+	}
+
+	if got.ETag != want.ETag || !bytes.Equal(got.Body, want.Body) || got.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("Get() = %+v, want %+v", got, want)
+	}
+
+	if _, ok = reopened.Get("missing"); ok {
+		t.Fatal("Get(missing) = true, want false")
+	}
+
+	cache.Set("other", &cloudcraft.CacheEntry{ETag: "other"})
+
+	// Reading "key" makes "other" the least recently used entry.
+	time.Sleep(10 * time.Millisecond)
+
+	if _, ok = cache.Get("key"); !ok {
+		t.Fatal("Get(key) = false, want true")
+	}
+
+	cache.Set("new", &cloudcraft.CacheEntry{ETag: "new"})
+
+	if _, ok = cache.Get("other"); ok {
+		t.Fatal("Get(other) = true, want it evicted")
+	}
+
+	for _, key := range []string{"key", "new"} {
+		if _, ok = reopened.Get(key); !ok {
+			t.Fatalf("Get(%s) = false, want true", key)
+		}
+	}
+
+	if cache.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", cache.Len())
+	}
+}
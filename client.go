@@ -7,17 +7,23 @@ package cloudcraft
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/DataDog/cloudcraft-go/internal/breaker"
 	"github.com/DataDog/cloudcraft-go/internal/endpoint"
-	"github.com/DataDog/cloudcraft-go/internal/meta"
+	"github.com/DataDog/cloudcraft-go/internal/ratelimit"
 	"github.com/DataDog/cloudcraft-go/internal/xerrors"
 	"github.com/DataDog/cloudcraft-go/internal/xhttp"
+	"github.com/DataDog/cloudcraft-go/internal/xos"
 )
 
 const (
@@ -25,13 +31,23 @@ const (
 	// Config.
 	ErrInvalidConfig xerrors.Error = "invalid config"
 
-	// ErrRequestFailed is returned when a request to the Cloudcraft API fails
-	// for unknown reasons.
+	// ErrRequestFailed is returned when a request to the Cloudcraft API fails.
+	// It is wrapped by APIError, which carries the details of the failure.
 	ErrRequestFailed xerrors.Error = "request failed with status code"
 
 	// ErrMaxRetriesExceeded is returned when the maximum number of retries is
-	// exceeded for HTTP requests.
+	// exceeded for HTTP requests. It is wrapped by RetryError, which carries
+	// the history of the attempts.
 	ErrMaxRetriesExceeded xerrors.Error = "maximum number of retries exceeded"
+
+	// ErrRetryBudgetExhausted is returned when a failed request is not retried
+	// because the RetryBudget of the client is exhausted. It is wrapped by
+	// RetryError, which carries the history of the attempts.
+	ErrRetryBudgetExhausted xerrors.Error = "retry budget exhausted"
+
+	// ErrResponseTooLarge is returned when the body of a response exceeds the
+	// maximum size set with Config.MaxResponseSize.
+	ErrResponseTooLarge xerrors.Error = "response body exceeds maximum size"
 )
 
 type (
@@ -46,12 +62,63 @@ type (
 		// httpClient is the underlying HTTP client used by the API client.
 		httpClient *http.Client
 
+		// transport is the innermost transport of httpClient, whose idle
+		// connections are released by Close. It is nil if the client did not
+		// build it.
+		transport http.RoundTripper
+
 		// retryPolicy specifies the policy used to retry failed requests.
 		retryPolicy *xhttp.RetryPolicy
 
+		// limiter throttles requests sent to the API. It is nil if rate
+		// limiting is disabled.
+		limiter *ratelimit.Limiter
+
+		// retryBudget caps the retries of all calls. It is nil if the retry
+		// budget is disabled.
+		retryBudget *ratelimit.Budget
+
+		// logger receives the events of the client. It is nil if logging is
+		// disabled.
+		logger *slog.Logger
+
+		// metrics records the measurements of the calls made by the client. It
+		// is nil if metrics are disabled.
+		metrics Metrics
+
+		// tracer creates the spans describing the calls made by the client.
+		tracer Tracer
+
+		// decoder negotiates and decompresses compressed responses. It is nil
+		// if compression is disabled.
+		decoder *xhttp.ContentDecoder
+
+		// cache stores the responses to GET requests to revalidate them. It is
+		// nil if caching is disabled.
+		cache Cache
+
+		// flights tracks the GET requests in flight to coalesce identical
+		// ones. It is nil if coalescing is disabled.
+		flights *flightGroup
+
+		// breaker stops calls to the API while it fails. It is nil if the
+		// circuit breaker is disabled.
+		breaker *breaker.Breaker
+
+		// endpoints tracks the health of the endpoints the client fails over
+		// between. It is nil if no fallback endpoints are configured.
+		endpoints *endpoint.Pool
+
 		// cfg specifies the configuration used by the API client.
 		cfg *Config
 
+		// credentials provides the API key of every request. It is swapped
+		// atomically by SetCredentials.
+		credentials atomic.Pointer[CredentialProvider]
+
+		// lifecycle tracks the calls in progress for Close.
+		lifecycle lifecycle
+
 		// Cloudcraft API service fields.
 		Azure     *AzureService
 		AWS       *AWSService
@@ -64,8 +131,18 @@ type (
 )
 
 // NewClient returns a new Client given a Config. If Config is nil, NewClient
-// will try to look up the configuration from the environment.
+// will try to look up the configuration from the environment; see
+// NewConfigFromEnv. When CLOUDCRAFT_PROFILE is set, the profile it selects is
+// loaded as well; see NewConfigFromProfile.
 func NewClient(cfg *Config) (*Client, error) {
+	if cfg == nil && xos.GetEnv(EnvProfile, "") != "" {
+		var err error
+
+		if cfg, err = NewConfigFromProfile(""); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+
 	if cfg == nil {
 		cfg = NewConfigFromEnv()
 	}
@@ -74,12 +151,12 @@ func NewClient(cfg *Config) (*Client, error) {
 		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
-	baseURL, err := endpoint.Parse(cfg.Scheme, cfg.Host, cfg.Port, cfg.Path)
+	endpoints, err := cfg.endpoints()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
-	cfg.endpoint = baseURL
+	cfg.endpoint = endpoints[0]
 
 	if cfg.MaxRetries <= 0 {
 		cfg.MaxRetries = DefaultMaxRetries
@@ -89,15 +166,40 @@ func NewClient(cfg *Config) (*Client, error) {
 		cfg.Timeout = DefaultTimeout
 	}
 
+	httpClient, transport, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
 	client := &Client{
-		httpClient: xhttp.NewClient(cfg.Timeout),
-		retryPolicy: &xhttp.RetryPolicy{
-			IsRetryable:   xhttp.DefaultIsRetryable,
-			MaxRetries:    cfg.MaxRetries,
-			MinRetryDelay: xhttp.DefaultMinRetryDelay,
-			MaxRetryDelay: xhttp.DefaultMaxRetryDelay,
-		},
-		cfg: cfg,
+		httpClient:  httpClient,
+		transport:   transport,
+		retryPolicy: newRetryPolicy(cfg),
+		retryBudget: newRetryBudget(cfg),
+		tracer:      cfg.Tracer,
+		metrics:     cfg.Metrics,
+		logger:      cfg.Logger,
+		decoder:     newContentDecoder(cfg),
+		endpoints:   newEndpointPool(cfg, endpoints),
+		cache:       cfg.Cache,
+		flights:     newFlightGroup(cfg),
+		breaker:     newCircuitBreaker(cfg),
+		cfg:         cfg,
+	}
+
+	credentials := cfg.Credentials
+	if credentials == nil {
+		credentials = NewStaticCredentials(cfg.Key)
+	}
+
+	client.credentials.Store(&credentials)
+
+	if client.tracer == nil {
+		client.tracer = noopTracer{}
+	}
+
+	if cfg.RateLimit > 0 {
+		client.limiter = ratelimit.New(cfg.RateLimit, cfg.RateLimitBurst)
 	}
 
 	client.common.client = client
@@ -192,15 +294,280 @@ type Response struct {
 	// Header contains the response headers.
 	Header http.Header
 
-	// Body contains the response body as a byte slice.
+	// Body contains the response body as a byte slice. It is empty for
+	// responses streamed to an io.Writer.
 	Body []byte
 
+	// BodyStats describes how the body was received. It is set for successful
+	// responses, including streamed ones.
+	BodyStats BodyStats
+
+	// CallStats describes how the call that produced the response was made:
+	// its final request, attempts and timing.
+	CallStats CallStats
+
+	// RateLimit is the request quota reported by the rate-limit headers of
+	// the response. It is nil if the response carries none.
+	RateLimit *RateLimit
+
 	// Status is the HTTP status code of the response.
 	Status int
+
+	// Cached reports whether the body was served from the Cache of the
+	// client, after the API confirmed with a 304 Not Modified response that
+	// it did not change.
+	Cached bool
+
+	// Coalesced reports whether the response was shared with an identical
+	// call already in flight, instead of being requested again. See
+	// Config.Coalesce.
+	Coalesced bool
+}
+
+// CallStats describes how a call to the Cloudcraft API was made.
+type CallStats struct {
+	// Method is the HTTP method of the request.
+	Method string
+
+	// URL is the URL of the last attempt, which reflects the endpoint the
+	// client failed over to, if any.
+	URL string
+
+	// AttemptDurations holds the time each attempt took until the response
+	// headers were received, in order. It excludes the backoff between
+	// attempts and the time spent reading the body; see BodyStats.
+	AttemptDurations []time.Duration
+
+	// Duration is the total time the call took, from the first attempt to the
+	// end of the body, backoff and rate limiter waits included.
+	Duration time.Duration
+
+	// Attempts is the number of attempts made, retries included.
+	Attempts int
+
+	// RetryBudgetExhausted reports whether a failed attempt was not retried
+	// because the RetryBudget of the client was exhausted.
+	RetryBudgetExhausted bool
+}
+
+// do performs an HTTP request using the underlying HTTP client and returns
+// the response with its body read into memory.
+//
+// If the API responds with a status code that indicates a failure, do returns
+// both a Response holding the error body and an *APIError.
+func (c *Client) do(req *http.Request) (*Response, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return nil, err
+	}
+	defer c.lifecycle.end()
+
+	// Calls with their own options may get a different result, so they are
+	// never shared.
+	if c.flights != nil && req.Method == http.MethodGet && requestOptionsFrom(req.Context()).empty() {
+		return c.coalesce(req)
+	}
+
+	return c.execute(req, nil)
+}
+
+// stream performs an HTTP request using the underlying HTTP client and copies
+// the response body to w as it is received. The Body of the returned Response
+// is always empty.
+//
+// Retries happen before any data is written to w. If copying the body fails,
+// part of it may have been written already.
+func (c *Client) stream(req *http.Request, w io.Writer) (*Response, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return nil, err
+	}
+	defer c.lifecycle.end()
+
+	return c.execute(req, w)
+}
+
+// call holds the details of a logical call to the Cloudcraft API gathered
+// while it is executed, to report it once done.
+type call struct {
+	start            time.Time
+	options          *requestOptions
+	op               operation
+	method           string
+	url              string
+	attemptDurations []time.Duration
+	attempts         int
+	bytesOut         int64
+	budgetExhausted  bool
+}
+
+// stats returns the CallStats of the call, as of now.
+func (c *call) stats() CallStats {
+	return CallStats{
+		Method:               c.method,
+		URL:                  c.url,
+		AttemptDurations:     c.attemptDurations,
+		Duration:             time.Since(c.start),
+		Attempts:             c.attempts,
+		RetryBudgetExhausted: c.budgetExhausted,
+	}
+}
+
+// execute implements do and stream. If w is nil, the response body is read
+// into the Body of the returned Response.
+func (c *Client) execute(req *http.Request, w io.Writer) (*Response, error) {
+	state := &call{
+		start:   time.Now(),
+		options: requestOptionsFrom(req.Context()),
+		op:      operationFrom(req.Context()),
+		method:  req.Method,
+		url:     req.URL.String(),
+	}
+
+	ctx := req.Context()
+
+	if state.options.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, state.options.timeout)
+		defer cancel()
+	}
+
+	ctx, span := c.tracer.Start(ctx, state.op.spanName(), state.op.attributes()...)
+
+	req, cached, cacheKey := c.revalidate(req, w)
+
+	resp, err := c.send(req.WithContext(ctx), state)
+	if err != nil {
+		response := errorResponse(err)
+
+		c.report(ctx, state, span, response, err)
+
+		return response, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		response := fromCache(resp, cached)
+
+		c.report(ctx, state, span, response, nil)
+
+		return response, nil
+	}
+
+	response, err := c.receive(resp, w)
+	if err == nil {
+		c.store(cacheKey, response)
+	}
+
+	c.report(ctx, state, span, response, err)
+
+	return response, err
+}
+
+// receive reads the body of a successful response, into memory if w is nil or
+// to w otherwise, and closes it.
+func (c *Client) receive(resp *http.Response, w io.Writer) (*Response, error) {
+	defer func() {
+		if err := xhttp.DrainResponseBody(resp); err != nil {
+			_ = resp.Body.Close()
+		}
+	}()
+
+	var buffer *bytes.Buffer
+
+	if w == nil {
+		if resp.ContentLength > 0 {
+			buffer = bytes.NewBuffer(make([]byte, 0, resp.ContentLength))
+		} else {
+			buffer = bytes.NewBuffer(make([]byte, 0))
+		}
+
+		w = buffer
+	}
+
+	response := &Response{
+		Header: resp.Header,
+		Status: resp.StatusCode,
+	}
+
+	stats, err := c.readBody(w, resp)
+
+	response.BodyStats = stats
+
+	if err != nil {
+		return response, err
+	}
+
+	if buffer != nil {
+		response.Body = buffer.Bytes()
+	}
+
+	return response, nil
+}
+
+// report completes the response to a call with its CallStats and RateLimit,
+// completes its span, logs it and records its metrics.
+//
+// A call whose duplicate check found the resource created by a previous
+// attempt is reported as successful, since the caller gets that resource.
+func (c *Client) report(ctx context.Context, state *call, span Span, response *Response, err error) {
+	if errors.Is(err, errDuplicateFound) {
+		err = nil
+	}
+
+	var status int
+
+	if response != nil {
+		status = response.Status
+
+		response.CallStats = state.stats()
+		response.RateLimit = parseRateLimit(response.Header, time.Now())
+	}
+
+	span.SetAttributes(Attribute{Key: AttributeAttempts, Value: state.attempts})
+
+	if status != 0 {
+		span.SetAttributes(Attribute{Key: AttributeHTTPStatusCode, Value: status})
+	}
+
+	span.End(err)
+
+	c.logCall(ctx, state, status, err)
+
+	if c.metrics == nil {
+		return
+	}
+
+	var bytesIn int64
+
+	if response != nil {
+		bytesIn = response.BodyStats.WireSize
+
+		// Error responses have no BodyStats, but their body is held in memory.
+		// Cached bodies served after a 304 Not Modified were not received.
+		if bytesIn == 0 && !response.Cached {
+			bytesIn = int64(len(response.Body))
+		}
+	}
+
+	c.metrics.RecordCall(CallMetrics{
+		Err:        err,
+		Service:    state.op.service,
+		Operation:  state.op.name,
+		Latency:    time.Since(state.start),
+		BytesIn:    bytesIn,
+		BytesOut:   state.bytesOut,
+		Attempts:   state.attempts,
+		StatusCode: status,
+	})
 }
 
-// do performs an HTTP request using the underlying HTTP client.
-func (c *Client) do(req *http.Request) (*Response, error) { //nolint:gocyclo // Necessary complexity.
+// send performs an HTTP request using the underlying HTTP client and returns
+// the successful response with its body left unread. The caller must close
+// the body. The attempts made and the bytes sent are recorded in state.
+//
+// Failed attempts are retried according to the retry policy of the client.
+// Requests that are not idempotent, such as POST and PUT requests, are only
+// retried when it is safe to do so; see canRetry.
+func (c *Client) send(req *http.Request, state *call) (*http.Response, error) { //nolint:gocyclo // Necessary complexity.
 	var (
 		attempt int
 		resp    *http.Response
@@ -217,75 +584,232 @@ func (c *Client) do(req *http.Request) (*Response, error) { //nolint:gocyclo //
 		}
 
 		req.Body = io.NopCloser(body)
+		state.bytesOut = int64(body.Len())
 
 		if err = req.Body.Close(); err != nil {
 			return nil, fmt.Errorf("%w", err)
 		}
 	}
 
-	for attempt = 0; attempt <= c.retryPolicy.MaxRetries; attempt++ {
+	var (
+		maxRetries = state.options.retries(c.retryPolicy.MaxRetries)
+		httpClient = state.options.httpClient(c.httpClient)
+	)
+
+	var (
+		history []RetryAttempt
+		stopped error
+	)
+
+	for attempt = 0; ; attempt++ {
+		if c.limiter != nil {
+			if _, err = c.limiter.Wait(req.Context()); err != nil {
+				return nil, fmt.Errorf("%w", err)
+			}
+		}
+
 		if body != nil {
 			req.Body = io.NopCloser(bytes.NewReader(body.Bytes()))
 		}
 
-		resp, err = c.httpClient.Do(req)
-		if err != nil || !c.retryPolicy.IsRetryable(resp, err) {
+		generation, openErr := c.allowAttempt()
+		if openErr != nil {
+			return nil, openErr
+		}
+
+		state.attempts++
+
+		routedReq, endpointIndex := c.route(req)
+
+		attemptReq, span := c.startAttempt(routedReq, state.attempts)
+
+		c.logRequest(attemptReq, state.attempts)
+
+		sent := time.Now()
+
+		resp, err = httpClient.Do(attemptReq) //nolint:bodyclose // closed below or by the caller
+
+		c.observeEndpoint(req, endpointIndex, resp, err)
+		c.recordAttempt(req, generation, resp, err)
+
+		if err == nil && c.decoder != nil {
+			c.decoder.Decode(resp)
+		}
+
+		elapsed := time.Since(sent)
+
+		state.url = attemptReq.URL.String()
+		state.attemptDurations = append(state.attemptDurations, elapsed)
+
+		c.logResponse(attemptReq, state.attempts, resp, err, elapsed)
+
+		endAttempt(span, resp, err)
+
+		observeAttempt(req.Context(), resp, err)
+
+		c.depositRetryBudget(req, resp, err)
+
+		if req.Context().Err() != nil || !c.retryPolicy.IsRetryable(resp, err) {
+			break
+		}
+
+		retry, check := canRetry(req, resp, err)
+		if !retry {
+			break
+		}
+
+		// The last failure is returned with its response body, so it is
+		// neither drained nor followed by a backoff. Without retries, it is
+		// returned as is.
+		if attempt >= maxRetries {
+			if maxRetries > 0 {
+				stopped = ErrMaxRetriesExceeded
+			}
+
+			break
+		}
+
+		if c.retryBudget != nil && !c.retryBudget.Withdraw() {
+			state.budgetExhausted = true
+			stopped = ErrRetryBudgetExhausted
+
 			break
 		}
 
+		// The failure is returned as is if the duplicate check cannot tell
+		// whether the request can be retried, so it is kept before the body
+		// is drained.
+		var failure error
+		if check != nil {
+			failure = attemptError(req, resp, err)
+		}
+
 		if resp != nil {
-			if err = xhttp.DrainResponseBody(resp); err != nil {
+			if drainErr := xhttp.DrainResponseBody(resp); drainErr != nil {
 				_ = resp.Body.Close()
 			}
 		}
 
-		waitErr := c.retryPolicy.Wait(req.Context(), attempt)
+		backoff := c.retryPolicy.Backoff(attempt, resp)
+
+		// Another endpoint is tried right away.
+		if c.failingOver(endpointIndex) {
+			backoff = 0
+		}
+
+		history = append(history, newRetryAttempt(resp, err, backoff))
+
+		// The next attempt would be rejected, so there is no point waiting.
+		if c.CircuitState() == CircuitOpen {
+			return nil, ErrCircuitOpen
+		}
+
+		c.logRetry(req, state.attempts, resp, err, backoff)
+
+		waitErr := xhttp.Sleep(req.Context(), backoff)
 		if waitErr != nil {
 			return nil, fmt.Errorf("%w", waitErr)
 		}
+
+		if check != nil {
+			found, checkErr := check(req.Context())
+
+			switch {
+			case checkErr != nil:
+				return nil, failure
+			case found:
+				return nil, errDuplicateFound
+			}
+		}
 	}
 
-	if resp == nil && attempt >= c.retryPolicy.MaxRetries {
-		return nil, fmt.Errorf("%w: %d", ErrMaxRetriesExceeded, attempt)
+	if err == nil && (resp.StatusCode <= http.StatusNoContent || notModified(req, resp)) {
+		return resp, nil
 	}
 
-	if err != nil {
-		select {
-		case <-req.Context().Done():
-			return nil, fmt.Errorf("%w", req.Context().Err())
-		default:
-			return nil, fmt.Errorf("%w", err)
+	if err != nil && req.Context().Err() != nil {
+		return nil, fmt.Errorf("%w", req.Context().Err())
+	}
+
+	cause := attemptError(req, resp, err)
+
+	if stopped != nil {
+		return nil, &RetryError{
+			Err:      cause,
+			Reason:   stopped,
+			Attempts: append(history, newRetryAttempt(resp, err, 0)),
 		}
 	}
 
+	return nil, cause
+}
+
+// attemptError returns the error of a failed attempt to send req, given the
+// response and error it produced. The body of an error response is read and
+// closed.
+func attemptError(req *http.Request, resp *http.Response, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
 	defer func() {
-		if err = xhttp.DrainResponseBody(resp); err != nil {
+		if err := xhttp.DrainResponseBody(resp); err != nil {
 			_ = resp.Body.Close()
 		}
 	}()
 
-	if resp.StatusCode > http.StatusNoContent {
-		return nil, fmt.Errorf("%w: %d", ErrRequestFailed, resp.StatusCode)
-	}
+	// The error body is informational only, so failing to read it is not an
+	// error.
+	errBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
 
-	var buffer *bytes.Buffer
+	return newAPIError(req, resp, errBody)
+}
+
+// copyBody copies r, the body of a response with the given content length, to
+// w. It fails with ErrResponseTooLarge if the body exceeds the maximum
+// response size of the client.
+func (c *Client) copyBody(w io.Writer, r io.Reader, contentLength int64) (int64, error) {
+	limit := c.cfg.MaxResponseSize
+	if limit <= 0 {
+		n, err := io.Copy(w, r)
+		if err != nil {
+			return n, fmt.Errorf("%w", err)
+		}
 
-	if resp.ContentLength > 0 {
-		buffer = bytes.NewBuffer(make([]byte, 0, resp.ContentLength))
-	} else {
-		buffer = bytes.NewBuffer(make([]byte, 0))
+		return n, nil
 	}
 
-	_, err = io.Copy(buffer, resp.Body)
+	if contentLength > limit {
+		return 0, fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, contentLength)
+	}
+
+	n, err := io.Copy(w, io.LimitReader(r, limit))
 	if err != nil {
-		return nil, fmt.Errorf("%w", err)
+		return n, fmt.Errorf("%w", err)
+	}
+
+	// Read one more byte to tell a body of exactly the maximum size apart from
+	// a larger one.
+	if extra, _ := r.Read(make([]byte, 1)); extra > 0 {
+		return n, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+
+	return n, nil
+}
+
+// errorResponse returns the Response to return alongside an error from send,
+// which holds the details of the response if the error is an APIError.
+func errorResponse(err error) *Response {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return nil
 	}
 
 	return &Response{
-		Header: resp.Header,
-		Body:   buffer.Bytes(),
-		Status: resp.StatusCode,
-	}, nil
+		Header: apiErr.Header,
+		Body:   apiErr.Body,
+		Status: apiErr.StatusCode,
+	}
 }
 
 // request is a convenience function for creating an HTTP request.
@@ -294,14 +818,35 @@ func (c *Client) request(
 	method, uri string,
 	body io.Reader,
 ) (*http.Request, error) {
+	key, err := c.key(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, uri, body)
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
 
+	options := requestOptionsFrom(ctx)
+
+	// The extra headers are set first so that they cannot override the ones
+	// set by the client.
+	for name, values := range options.header {
+		req.Header[name] = append([]string(nil), values...)
+	}
+
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.cfg.Key)
-	req.Header.Set("User-Agent", meta.UserAgent)
+	req.Header.Set("Authorization", "Bearer "+key)
+	req.Header.Set("User-Agent", options.userAgentHeader())
+
+	if c.decoder != nil {
+		req.Header.Set("Accept-Encoding", c.decoder.AcceptEncoding())
+	}
+
+	if key := idempotencyKeyFrom(ctx); key != "" && !isIdempotent(method) {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
 
 	return req, nil
 }
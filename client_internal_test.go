@@ -111,11 +111,12 @@ func TestDo(t *testing.T) {
 				Header: http.Header{
 					"Content-Length": []string{"13"},
 					"Content-Type":   []string{"text/plain; charset=utf-8"},
-					"Date": []string{
-						time.Now().In(time.UTC).Format(http.TimeFormat),
-					},
 				},
-				Body:   []uint8{'H', 'e', 'l', 'l', 'o', ',', ' ', 'W', 'o', 'r', 'l', 'd', '!'},
+				Body: []uint8{'H', 'e', 'l', 'l', 'o', ',', ' ', 'W', 'o', 'r', 'l', 'd', '!'},
+				BodyStats: BodyStats{
+					WireSize: 13,
+					Size:     13,
+				},
 				Status: http.StatusOK,
 			},
 			wantErr: false,
@@ -200,6 +201,10 @@ func TestDo(t *testing.T) {
 				Port:   endpoint.Port(),
 				Path:   DefaultPath,
 				Key:    "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				RetryPolicy: &RetryPolicy{
+					MinRetryDelay: time.Millisecond,
+					MaxRetryDelay: time.Millisecond,
+				},
 			}
 
 			client, err := NewClient(cfg)
@@ -221,6 +226,24 @@ func TestDo(t *testing.T) {
 				return
 			}
 
+			// The Date header depends on when the server handled the request,
+			// so it is checked for presence only.
+			if got.Header.Get("Date") == "" {
+				t.Fatal("Do() response is missing the Date header")
+			}
+
+			got.Header.Del("Date")
+
+			// Durations depend on the machine running the tests.
+			got.BodyStats.ReadDuration = 0
+			got.BodyStats.DecodeDuration = 0
+
+			if got.CallStats.Method != http.MethodGet || got.CallStats.Attempts != 1 || len(got.CallStats.AttemptDurations) != 1 {
+				t.Fatalf("Do() CallStats = %+v, want a single GET attempt", got.CallStats)
+			}
+
+			got.CallStats = CallStats{}
+
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Fatalf("Do() = %v, want %v", got, tt.want)
 			}
@@ -449,3 +472,68 @@ func TestDo_Retries_GET(t *testing.T) {
 		})
 	}
 }
+
+func TestNewClient_Transport(t *testing.T) {
+	t.Parallel()
+
+	shared := &http.Transport{}
+
+	tests := []struct {
+		name      string
+		give      func(cfg *Config)
+		wantOwned bool
+	}{
+		{
+			name:      "Built by the client",
+			give:      func(*Config) {},
+			wantOwned: true,
+		},
+		{
+			name: "Supplied transport",
+			give: func(cfg *Config) {
+				cfg.Transport = shared
+			},
+			wantOwned: false,
+		},
+		{
+			name: "Supplied HTTP client without a transport",
+			give: func(cfg *Config) {
+				cfg.HTTPClient = &http.Client{}
+			},
+			wantOwned: false,
+		},
+		{
+			name: "Supplied transport cloned for TLS",
+			give: func(cfg *Config) {
+				cfg.Transport = shared
+				cfg.TLS = &TLSConfig{ServerName: "cloudcraft.example.com"}
+			},
+			wantOwned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewConfig("not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=")
+			tt.give(cfg)
+
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			// Close only releases the connections of transports it owns.
+			if owned := client.transport != nil; owned != tt.wantOwned {
+				t.Fatalf("transport owned = %t, want %t", owned, tt.wantOwned)
+			}
+
+			if client.transport == shared || client.transport == http.DefaultTransport {
+				t.Fatal("transport is shared with other parts of the program")
+			}
+		})
+	}
+}
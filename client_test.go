@@ -5,8 +5,15 @@
 package cloudcraft_test
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/DataDog/cloudcraft-go"
 )
@@ -82,6 +89,15 @@ func TestNewClientWithNilConfig(t *testing.T) {
 	t.Setenv("CLOUDCRAFT_API_KEY", "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=")
 	t.Setenv("CLOUDCRAFT_TIMEOUT", "80s")
 
+	// Without a selected profile, the config file is not read.
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("[default]\nhots = cloudcraft.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(cloudcraft.EnvConfigFile, path)
+	t.Setenv(cloudcraft.EnvProfile, "")
+
 	client, err := cloudcraft.NewClient(nil)
 	if err != nil {
 		t.Fatalf("Unexpected error for nil config: %v", err)
@@ -90,4 +106,100 @@ func TestNewClientWithNilConfig(t *testing.T) {
 	if client == nil {
 		t.Error("Expected non-nil client, got nil")
 	}
+
+	// A selected profile is read, and reported when invalid.
+	t.Setenv(cloudcraft.EnvProfile, "default")
+
+	if _, err = cloudcraft.NewClient(nil); !errors.Is(err, cloudcraft.ErrInvalidProfile) {
+		t.Fatalf("NewClient(nil) error = %v, want %v", err, cloudcraft.ErrInvalidProfile)
+	}
+}
+
+func TestResponse_CallStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		failures     int32
+		finalStatus  int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "First attempt succeeds",
+			finalStatus:  http.StatusOK,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Succeeds after retries",
+			failures:     2,
+			finalStatus:  http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "Fails without retries",
+			finalStatus:  http.StatusNotFound,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if calls.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+
+					return
+				}
+
+				w.WriteHeader(tt.finalStatus)
+				w.Write([]byte(`{}`))
+			}))
+			defer ts.Close()
+
+			cfg := &cloudcraft.Config{
+				Endpoint: ts.URL,
+				Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+			}
+
+			fastRetries(cfg)
+
+			client, err := cloudcraft.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, resp, err := client.User.Me(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("User.Me() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			stats := resp.CallStats
+
+			if stats.Method != http.MethodGet || stats.URL != ts.URL+"/user/me" {
+				t.Fatalf("CallStats request = %s %s, want GET %s/user/me", stats.Method, stats.URL, ts.URL)
+			}
+
+			if stats.Attempts != tt.wantAttempts || len(stats.AttemptDurations) != tt.wantAttempts {
+				t.Fatalf("CallStats attempts = %d, %v, want %d", stats.Attempts, stats.AttemptDurations, tt.wantAttempts)
+			}
+
+			var total time.Duration
+
+			for _, d := range stats.AttemptDurations {
+				total += d
+			}
+
+			if stats.Duration < total {
+				t.Fatalf("CallStats.Duration = %v, want at least the sum of the attempts, %v", stats.Duration, total)
+			}
+		})
+	}
 }
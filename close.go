@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+)
+
+// ErrClientClosed is returned by the calls made with a Client once Close has
+// been called.
+const ErrClientClosed xerrors.Error = "client is closed"
+
+// lifecycle tracks the calls in progress of a client, so that Close can wait
+// for them to return.
+type lifecycle struct {
+	calls  sync.WaitGroup
+	mu     sync.Mutex
+	closed bool
+}
+
+// begin registers a new call, or fails with ErrClientClosed if the client is
+// closed. Every successful call to begin must be matched by a call to end.
+func (l *lifecycle) begin() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClientClosed
+	}
+
+	l.calls.Add(1)
+
+	return nil
+}
+
+// end marks a call registered with begin as returned.
+func (l *lifecycle) end() {
+	l.calls.Done()
+}
+
+// close stops new calls from being registered and returns a channel that is
+// closed once all the calls in progress have returned.
+func (l *lifecycle) close() <-chan struct{} {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		l.calls.Wait()
+		close(done)
+	}()
+
+	return done
+}
+
+// Close shuts the client down gracefully. New calls fail right away with
+// ErrClientClosed, while Close waits for the calls in progress to return,
+// including those waiting before a retry. It then releases the idle
+// connections of the transport built by the client. A transport supplied with
+// Config.Transport or Config.HTTPClient, or http.DefaultTransport, is left
+// untouched since it may be shared; close its idle connections yourself.
+//
+// If ctx is done before all calls return, Close releases the idle connections
+// anyway and returns the error of ctx. The calls still in progress are not
+// interrupted; cancel their own context to stop them.
+//
+// Close can be called more than once, and every call waits for the calls in
+// progress.
+func (c *Client) Close(ctx context.Context) error {
+	done := c.lifecycle.close()
+
+	defer c.closeIdleConnections()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w", ctx.Err())
+	}
+}
+
+// closeIdleConnections closes the idle connections of the transport built by
+// the client, if any. The HTTP clients made for calls with their own timeout
+// share this transport.
+func (c *Client) closeIdleConnections() {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+
+	if transport, ok := c.transport.(closeIdler); ok {
+		transport.CloseIdleConnections()
+	}
+}
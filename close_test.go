@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+)
+
+func TestClient_Close(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls    atomic.Int32
+		received = make(chan struct{}, 1)
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			received <- struct{}{}
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint: ts.URL,
+		Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		RetryPolicy: &cloudcraft.RetryPolicy{
+			Jitter:        cloudcraft.NoJitter,
+			MinRetryDelay: 100 * time.Millisecond,
+			MaxRetryDelay: 100 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+	result := make(chan error, 1)
+
+	go func() {
+		_, _, err := client.User.Me(ctx)
+		result <- err
+	}()
+
+	// Close while the call waits before its retry.
+	<-received
+
+	if err = client.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	select {
+	case err = <-result:
+		if err != nil {
+			t.Fatalf("User.Me() error = %v", err)
+		}
+	default:
+		t.Fatal("Close() returned before the call in progress")
+	}
+
+	if _, _, err = client.User.Me(ctx); !errors.Is(err, cloudcraft.ErrClientClosed) {
+		t.Fatalf("User.Me() after Close() error = %v, want %v", err, cloudcraft.ErrClientClosed)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls to the API = %d, want 2", got)
+	}
+}
+
+func TestClient_Close_Deadline(t *testing.T) {
+	t.Parallel()
+
+	var (
+		received = make(chan struct{})
+		release  = make(chan struct{})
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(received)
+		<-release
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint: ts.URL,
+		Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	result := make(chan error, 1)
+
+	go func() {
+		_, _, err := client.User.Me(context.Background())
+		result <- err
+	}()
+
+	<-received
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err = client.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The call in progress is not interrupted.
+	close(release)
+
+	if err = <-result; err != nil {
+		t.Fatalf("User.Me() error = %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// flight is a call to the Cloudcraft API shared by identical concurrent
+// requests.
+type flight struct {
+	// done is closed once the call is complete.
+	done chan struct{}
+
+	// response is a private copy of the response of the call, which followers
+	// copy in turn.
+	response *Response
+
+	// err is the error returned by the call.
+	err error
+}
+
+// flightGroup tracks the calls in flight, keyed by requestKey.
+type flightGroup struct {
+	flights map[string]*flight
+	mu      sync.Mutex
+}
+
+// newFlightGroup returns the group used to coalesce requests given a Config,
+// or nil if coalescing is disabled.
+func newFlightGroup(cfg *Config) *flightGroup {
+	if !cfg.Coalesce {
+		return nil
+	}
+
+	return &flightGroup{flights: make(map[string]*flight)}
+}
+
+// join returns the flight for key, and whether the caller leads it, in which
+// case it must make the call and then land the flight.
+func (g *flightGroup) join(key string) (*flight, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if f, ok := g.flights[key]; ok {
+		return f, false
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+
+	return f, true
+}
+
+// land removes the flight for key and releases its followers.
+func (g *flightGroup) land(key string, f *flight) {
+	g.mu.Lock()
+	delete(g.flights, key)
+	g.mu.Unlock()
+
+	close(f.done)
+}
+
+// coalesce performs req like do, sharing the call with identical GET requests
+// made with the same credentials and without request options while it is in
+// flight. Every caller gets its
+// own copy of the Response and of the error, and the Response is marked as
+// Coalesced for the followers.
+//
+// Followers whose context is done stop waiting. If the call fails because the
+// context of the leader is done, followers whose context is still alive make
+// the request themselves.
+func (c *Client) coalesce(req *http.Request) (*Response, error) {
+	key := requestKey(req)
+
+	f, leader := c.flights.join(key)
+	if leader {
+		defer c.flights.land(key, f)
+
+		response, err := c.execute(req, nil)
+
+		f.response, f.err = response.clone(), err
+
+		return response, err
+	}
+
+	select {
+	case <-f.done:
+	case <-req.Context().Done():
+		return nil, fmt.Errorf("%w", req.Context().Err())
+	}
+
+	if isContextError(f.err) && req.Context().Err() == nil {
+		return c.execute(req, nil)
+	}
+
+	response := f.response.clone()
+	if response != nil {
+		response.Coalesced = true
+	}
+
+	return response, cloneError(f.err)
+}
+
+// clone returns a deep copy of the Response, or nil if it is nil.
+func (r *Response) clone() *Response {
+	if r == nil {
+		return nil
+	}
+
+	clone := *r
+	clone.Header = r.Header.Clone()
+	clone.Body = slices.Clone(r.Body)
+	clone.CallStats.AttemptDurations = slices.Clone(r.CallStats.AttemptDurations)
+
+	if r.RateLimit != nil {
+		rateLimit := *r.RateLimit
+		clone.RateLimit = &rateLimit
+	}
+
+	return &clone
+}
+
+// cloneError returns a deep copy of err if it is an *APIError or a
+// *RetryError, which hold the details of a response. Other errors are not
+// modified after they are returned, so they are returned as is.
+func cloneError(err error) error {
+	switch err := err.(type) { //nolint:errorlint // only the errors returned as is by execute are copied
+	case *APIError:
+		clone := *err
+		clone.Header = err.Header.Clone()
+		clone.Body = slices.Clone(err.Body)
+
+		if err.Payload != nil {
+			payload := *err.Payload
+			clone.Payload = &payload
+		}
+
+		return &clone
+	case *RetryError:
+		clone := *err
+		clone.Attempts = slices.Clone(err.Attempts)
+		clone.Err = cloneError(err.Err)
+
+		return &clone
+	default:
+		return err
+	}
+}
+
+// isContextError reports whether err is caused by a canceled context or an
+// expired deadline.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
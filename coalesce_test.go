@@ -0,0 +1,293 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestClient_Coalesce(t *testing.T) {
+	t.Parallel()
+
+	const callers = 5
+
+	validTestData := xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "get-valid.json"))
+
+	tests := []struct {
+		name        string
+		giveOptions []cloudcraft.RequestOption
+		coalesce    bool
+		wantCalls   int32
+	}{
+		{
+			name:      "Coalescing enabled",
+			coalesce:  true,
+			wantCalls: 1,
+		},
+		{
+			name:        "Coalescing enabled with request options",
+			giveOptions: []cloudcraft.RequestOption{cloudcraft.WithMaxRetries(0)},
+			coalesce:    true,
+			wantCalls:   callers,
+		},
+		{
+			name:      "Coalescing disabled",
+			coalesce:  false,
+			wantCalls: callers,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				calls   atomic.Int32
+				arrived = make(chan struct{}, callers)
+				release = make(chan struct{})
+			)
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls.Add(1)
+				arrived <- struct{}{}
+				<-release
+
+				w.Header().Set("X-RateLimit-Limit", "100")
+				w.Header().Set("X-RateLimit-Remaining", "99")
+				w.WriteHeader(http.StatusOK)
+				w.Write(validTestData)
+			}))
+			defer ts.Close()
+
+			client, err := cloudcraft.NewClient(&cloudcraft.Config{
+				Endpoint: ts.URL,
+				Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				Coalesce: tt.coalesce,
+			})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			var (
+				wg        sync.WaitGroup
+				responses = make([]*cloudcraft.Response, callers)
+				errs      = make([]error, callers)
+			)
+
+			get := func(i int) {
+				defer wg.Done()
+
+				ctx := cloudcraft.ContextWithRequestOptions(context.Background(), tt.giveOptions...)
+
+				_, responses[i], errs[i] = client.Blueprint.Get(ctx, "blueprint-id")
+			}
+
+			// The first call reaches the server before the others are made.
+			wg.Add(callers)
+
+			go get(0)
+
+			<-arrived
+
+			for i := 1; i < callers; i++ {
+				go get(i)
+			}
+
+			// Give the other calls time to join the first one.
+			time.Sleep(100 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Fatalf("calls to the API = %d, want %d", got, tt.wantCalls)
+			}
+
+			var coalesced int32
+
+			for i := range responses {
+				if errs[i] != nil {
+					t.Fatalf("Blueprint.Get() error = %v", errs[i])
+				}
+
+				if responses[i].Coalesced {
+					coalesced++
+				}
+
+				if !bytes.Equal(responses[i].Body, validTestData) {
+					t.Fatalf("Blueprint.Get() body = %s, want %s", responses[i].Body, validTestData)
+				}
+			}
+
+			if want := callers - tt.wantCalls; coalesced != want {
+				t.Fatalf("coalesced responses = %d, want %d", coalesced, want)
+			}
+
+			// Every caller owns its response.
+			responses[0].Body[0] = 'x'
+			responses[0].CallStats.AttemptDurations[0] = -1
+			responses[0].RateLimit.Remaining = -1
+
+			for _, response := range responses[1:] {
+				if response.Body[0] == 'x' {
+					t.Fatal("responses share their body")
+				}
+
+				if response.CallStats.AttemptDurations[0] == -1 {
+					t.Fatal("responses share their attempt durations")
+				}
+
+				if response.RateLimit.Remaining == -1 {
+					t.Fatal("responses share their rate limit")
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Coalesce_Credentials(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int32
+		arrived = make(chan struct{}, 2)
+		release = make(chan struct{})
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		arrived <- struct{}{}
+		<-release
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	credentials := cloudcraft.NewStaticCredentials("not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=")
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint:    ts.URL,
+		Credentials: credentials,
+		Coalesce:    true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	me := func() {
+		defer wg.Done()
+
+		if _, _, err := client.User.Me(context.Background()); err != nil {
+			t.Errorf("User.Me() error = %v", err)
+		}
+	}
+
+	go me()
+
+	<-arrived
+
+	// A call made with other credentials is not shared.
+	if err = credentials.SetKey("not-a-real-key-qNx7ZzX0ibwKcqYSdFBJeMm5Ymtd="); err != nil {
+		t.Fatalf("SetKey() error = %v", err)
+	}
+
+	go me()
+
+	<-arrived
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls to the API = %d, want 2", got)
+	}
+}
+
+func TestClient_Coalesce_Error(t *testing.T) {
+	t.Parallel()
+
+	var (
+		arrived = make(chan struct{}, 1)
+		release = make(chan struct{})
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		arrived <- struct{}{}
+		<-release
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"Not Found"}`))
+	}))
+	defer ts.Close()
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint: ts.URL,
+		Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		Coalesce: true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, 2)
+	)
+
+	get := func(i int) {
+		defer wg.Done()
+
+		_, _, errs[i] = client.Blueprint.Get(context.Background(), "blueprint-id")
+	}
+
+	wg.Add(2)
+
+	go get(0)
+
+	<-arrived
+
+	go get(1)
+
+	// Give the second call time to join the first one.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	apiErrs := make([]*cloudcraft.APIError, len(errs))
+
+	for i, err := range errs {
+		if !errors.As(err, &apiErrs[i]) {
+			t.Fatalf("Blueprint.Get() error = %v, want an *APIError", err)
+		}
+	}
+
+	// Every caller owns its error.
+	apiErrs[0].Body[0] = 'x'
+	apiErrs[0].Header.Set("Content-Type", "text/plain")
+	apiErrs[0].Payload.Error = "changed"
+
+	if apiErrs[1].Body[0] == 'x' || apiErrs[1].Header.Get("Content-Type") != "application/json" ||
+		apiErrs[1].Payload.Error != "Not Found" {
+		t.Fatalf("errors share their details: %+v", apiErrs[1])
+	}
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/xhttp"
+)
+
+// Decompressor returns a reader that decompresses the data read from r.
+//
+// Decompressors let the client accept content codings other than gzip, such
+// as zstd or brotli, without the SDK depending on their implementations.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+// BodyStats describes how the body of a response was received, before and
+// after decompression.
+type BodyStats struct {
+	// Encoding is the content coding the body was compressed with, such as
+	// "gzip". It is empty if the body was not compressed.
+	Encoding string
+
+	// WireSize is the number of bytes of the body received from the network,
+	// before decompression.
+	WireSize int64
+
+	// Size is the number of bytes of the body after decompression.
+	Size int64
+
+	// ReadDuration is the time spent receiving the body from the network.
+	ReadDuration time.Duration
+
+	// DecodeDuration is the time spent decompressing the body.
+	DecodeDuration time.Duration
+}
+
+// newContentDecoder returns the ContentDecoder used by the client given a
+// Config, or nil if compression is disabled.
+func newContentDecoder(cfg *Config) *xhttp.ContentDecoder {
+	if !cfg.Compression {
+		return nil
+	}
+
+	decoders := map[string]xhttp.Decoder{
+		xhttp.EncodingGzip: xhttp.DecodeGzip,
+	}
+
+	for name, decompressor := range cfg.Decompressors {
+		decoders[name] = xhttp.Decoder(decompressor)
+	}
+
+	return xhttp.NewContentDecoder(decoders)
+}
+
+// readBody copies the body of resp to w, up to the maximum response size, and
+// reports how it was received.
+func (c *Client) readBody(w io.Writer, resp *http.Response) (BodyStats, error) {
+	body := &xhttp.MeteredReader{R: resp.Body}
+
+	size, err := c.copyBody(w, body, resp.ContentLength)
+
+	stats := BodyStats{
+		WireSize:     size,
+		Size:         size,
+		ReadDuration: body.Elapsed,
+	}
+
+	if decoded, ok := resp.Body.(*xhttp.DecodedBody); ok {
+		stats.Encoding = decoded.Encoding()
+		stats.WireSize = decoded.WireSize()
+		stats.ReadDuration = decoded.ReadDuration()
+		stats.DecodeDuration = body.Elapsed - stats.ReadDuration
+	}
+
+	return stats, err
+}
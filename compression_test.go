@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestClient_Compression(t *testing.T) {
+	t.Parallel()
+
+	var (
+		budgetData   = xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "export-budget-valid.csv"))
+		errorPayload = []byte(`{"statusCode":404,"error":"Not Found","message":"Blueprint not found"}`)
+		ctx          = context.Background()
+	)
+
+	const id = "0f1a4e20-a887-4467-a37b-1bc7a3deb9a9"
+
+	tests := []struct {
+		name            string
+		compression     bool
+		decompressors   map[string]cloudcraft.Decompressor
+		maxResponseSize int64
+		giveStatus      int
+		giveBody        []byte
+		wantAccept      string
+		wantEncoding    string
+		wantBody        []byte
+		wantErr         error
+	}{
+		{
+			name:         "Compressed response",
+			compression:  true,
+			giveStatus:   http.StatusOK,
+			giveBody:     budgetData,
+			wantAccept:   "gzip",
+			wantEncoding: "gzip",
+			wantBody:     budgetData,
+		},
+		{
+			name:       "Compression disabled",
+			giveStatus: http.StatusOK,
+			giveBody:   budgetData,
+			wantBody:   budgetData,
+		},
+		{
+			name:        "Custom decompressor",
+			compression: true,
+			decompressors: map[string]cloudcraft.Decompressor{
+				"identity-test": func(r io.Reader) (io.ReadCloser, error) {
+					return io.NopCloser(r), nil
+				},
+			},
+			giveStatus:   http.StatusOK,
+			giveBody:     budgetData,
+			wantAccept:   "identity-test, gzip",
+			wantEncoding: "gzip",
+			wantBody:     budgetData,
+		},
+		{
+			name:            "Maximum size applies after decompression",
+			compression:     true,
+			maxResponseSize: int64(len(budgetData) - 1),
+			giveStatus:      http.StatusOK,
+			giveBody:        budgetData,
+			wantAccept:      "gzip",
+			wantErr:         cloudcraft.ErrResponseTooLarge,
+		},
+		{
+			name:        "Compressed error response",
+			compression: true,
+			giveStatus:  http.StatusNotFound,
+			giveBody:    errorPayload,
+			wantAccept:  "gzip",
+			wantErr:     cloudcraft.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var compressed bytes.Buffer
+
+			zw := gzip.NewWriter(&compressed)
+			zw.Write(tt.giveBody)
+			zw.Close()
+
+			var gotAccept string
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotAccept = r.Header.Get("Accept-Encoding")
+
+				if gotAccept == "" {
+					w.WriteHeader(tt.giveStatus)
+					w.Write(tt.giveBody)
+
+					return
+				}
+
+				w.Header().Set("Content-Encoding", "gzip")
+				w.WriteHeader(tt.giveStatus)
+				w.Write(compressed.Bytes())
+			}))
+			defer ts.Close()
+
+			endpoint, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+				cfg.Compression = tt.compression
+				cfg.Decompressors = tt.decompressors
+				cfg.MaxResponseSize = tt.maxResponseSize
+			})
+
+			got, resp, err := client.Blueprint.ExportBudget(ctx, id, "csv", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Blueprint.ExportBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if gotAccept != tt.wantAccept {
+				t.Fatalf("Accept-Encoding = %q, want %q", gotAccept, tt.wantAccept)
+			}
+
+			var apiErr *cloudcraft.APIError
+			if errors.As(err, &apiErr) {
+				if apiErr.Payload == nil || apiErr.Payload.Message != "Blueprint not found" {
+					t.Fatalf("APIError.Payload = %+v, want the decompressed payload", apiErr.Payload)
+				}
+
+				return
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !bytes.Equal(got, tt.wantBody) {
+				t.Fatalf("Blueprint.ExportBudget() = %q, want %q", got, tt.wantBody)
+			}
+
+			stats := resp.BodyStats
+
+			if stats.Encoding != tt.wantEncoding {
+				t.Fatalf("BodyStats.Encoding = %q, want %q", stats.Encoding, tt.wantEncoding)
+			}
+
+			if stats.Size != int64(len(tt.wantBody)) {
+				t.Fatalf("BodyStats.Size = %d, want %d", stats.Size, len(tt.wantBody))
+			}
+
+			wantWireSize := int64(len(tt.wantBody))
+			if tt.wantEncoding != "" {
+				wantWireSize = int64(compressed.Len())
+			}
+
+			if stats.WireSize != wantWireSize {
+				t.Fatalf("BodyStats.WireSize = %d, want %d", stats.WireSize, wantWireSize)
+			}
+
+			if stats.ReadDuration <= 0 {
+				t.Fatalf("BodyStats.ReadDuration = %v, want a positive duration", stats.ReadDuration)
+			}
+
+			if stats.DecodeDuration < 0 || (tt.wantEncoding == "" && stats.DecodeDuration != 0) {
+				t.Fatalf("BodyStats.DecodeDuration = %v", stats.DecodeDuration)
+			}
+		})
+	}
+}
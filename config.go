@@ -5,7 +5,13 @@
 package cloudcraft
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 	"net/url"
+	"reflect"
+	"slices"
 	"time"
 
 	"github.com/DataDog/cloudcraft-go/internal/xerrors"
@@ -30,6 +36,10 @@ const (
 	// ErrInvalidKey is returned when a Config is created with an invalid API
 	// key.
 	ErrInvalidKey xerrors.Error = "invalid API key; length must be 44"
+
+	// ErrInvalidMaxResponseSize is returned when a Config is created with a
+	// negative maximum response size.
+	ErrInvalidMaxResponseSize xerrors.Error = "invalid maximum response size; cannot be negative"
 )
 
 const (
@@ -62,7 +72,12 @@ const (
 	EnvPath       string = "CLOUDCRAFT_PATH"
 	EnvMaxRetries string = "CLOUDCRAFT_MAX_RETRIES"
 	EnvTimeout    string = "CLOUDCRAFT_TIMEOUT"
-	EnvAPIKey     string = "CLOUDCRAFT_API_KEY" //nolint:gosec // false positive
+	EnvAPIKey     string = "CLOUDCRAFT_API_KEY"      //nolint:gosec // false positive
+	EnvAPIKeyFile string = "CLOUDCRAFT_API_KEY_FILE" //nolint:gosec // false positive
+	EnvDebug      string = "CLOUDCRAFT_DEBUG"
+	EnvEndpoint   string = "CLOUDCRAFT_ENDPOINT"
+	EnvProfile    string = "CLOUDCRAFT_PROFILE"
+	EnvConfigFile string = "CLOUDCRAFT_CONFIG_FILE"
 )
 
 // Config holds the basic configuration for the Cloudcraft API.
@@ -106,6 +121,16 @@ type Config struct {
 	// This field is optional.
 	Path string
 
+	// Endpoint is the full base URL of the Cloudcraft API, such as
+	// "https://api.cloudcraft.co/". When set, it takes precedence over the
+	// Scheme, Host, Port and Path fields.
+	//
+	// If not set, the value of the CLOUDCRAFT_ENDPOINT environment variable is
+	// used by NewConfigFromEnv and NewConfigFromProfile.
+	//
+	// This field is optional.
+	Endpoint string
+
 	// Key is the API key used to authenticate with the Cloudcraft API.
 	//
 	// This field is required. [Learn more].
@@ -113,11 +138,22 @@ type Config struct {
 	// [Learn more]: https://developers.cloudcraft.co/#authentication
 	Key string
 
+	// Credentials provides the API key of every request, which lets the key
+	// be rotated while the client is running. When set, Key is ignored. See
+	// StaticCredentials, EnvCredentials, FileCredentials and
+	// CommandCredentials for the built-in providers.
+	//
+	// If not set, Key is used.
+	//
+	// This field is optional.
+	Credentials CredentialProvider
+
 	// MaxRetries is the maximum number of times the client will retry a request
 	// if it fails.
 	//
 	// If not set, the value of the CLOUDCRAFT_MAX_RETRIES environment variable
 	// is used. If the environment variable is not set, the default value is 3.
+	// It can be overridden for a call with WithMaxRetries.
 	//
 	// This field is optional.
 	MaxRetries int
@@ -127,10 +163,216 @@ type Config struct {
 	//
 	// If not set, the value of the CLOUDCRAFT_TIMEOUT environment variable is
 	// used. If the environment variable is not set, the default value is 80
-	// seconds.
+	// seconds. It applies to every attempt, and can be overridden for a call
+	// with WithAttemptTimeout.
 	//
 	// This field is optional.
 	Timeout time.Duration
+
+	// HTTPClient is the HTTP client used to send requests to the Cloudcraft
+	// API. The client is copied before use, so it is never modified.
+	//
+	// If not set, a client with sane defaults and the configured Timeout is
+	// used.
+	//
+	// This field is optional.
+	HTTPClient *http.Client
+
+	// RetryPolicy defines how failed requests are retried, including the
+	// backoff bounds, the jitter strategy and which failures are retryable.
+	//
+	// If not set, or for any of its fields left unset, the defaults described
+	// in RetryPolicy are used.
+	//
+	// This field is optional.
+	RetryPolicy *RetryPolicy
+
+	// RetryBudget caps the retries of the client as a whole, on top of the
+	// MaxRetries of every call, so that an outage does not multiply the
+	// number of requests sent to the Cloudcraft API. See RetryBudget.
+	//
+	// If not set, retries are only limited by MaxRetries.
+	//
+	// This field is optional.
+	RetryBudget *RetryBudget
+
+	// Transport is the HTTP transport used to send requests to the Cloudcraft
+	// API. It replaces the transport of HTTPClient, which makes it possible to
+	// supply a custom proxy, dialer or test transport while keeping the
+	// default client settings.
+	//
+	// This field is optional.
+	Transport http.RoundTripper
+
+	// FallbackEndpoints is an ordered list of full base URLs of the
+	// Cloudcraft API that the client fails over to when the primary endpoint
+	// returns connection errors or server errors. An endpoint that fails is
+	// avoided for the EndpointCooldown, during which the next healthy one in
+	// the list is used, and a failed attempt is retried on it right away if
+	// the request can be retried.
+	//
+	// This field is optional.
+	FallbackEndpoints []string
+
+	// EndpointCooldown is how long an endpoint that failed is avoided when
+	// FallbackEndpoints are set.
+	//
+	// If not set, the default value is 30 seconds.
+	//
+	// This field is optional.
+	EndpointCooldown time.Duration
+
+	// CircuitBreaker enables a circuit breaker that stops sending requests to
+	// the Cloudcraft API while it fails, so that calls fail fast with
+	// ErrCircuitOpen during an outage instead of sitting through their
+	// retries. See CircuitBreakerConfig.
+	//
+	// If not set, there is no circuit breaker.
+	//
+	// This field is optional.
+	CircuitBreaker *CircuitBreakerConfig
+
+	// TLS customizes the TLS settings used to connect to the Cloudcraft API:
+	// extra root CAs, a client certificate for mutual TLS, the minimum TLS
+	// version and the server name. It applies to the transport of HTTPClient,
+	// or to Transport, which must then be an *http.Transport.
+	//
+	// If not set, the system roots and TLS 1.3 are used.
+	//
+	// This field is optional.
+	TLS *TLSConfig
+
+	// Middleware is an ordered list of middleware that wraps every request
+	// made to the Cloudcraft API, retries included. The first middleware in
+	// the list is the outermost one.
+	//
+	// This field is optional.
+	Middleware []Middleware
+
+	// RateLimit is the maximum number of requests per second sent to the
+	// Cloudcraft API by the client, across all services. Every attempt,
+	// retries included, waits for the rate limiter before being sent.
+	//
+	// If not set, requests are not rate limited.
+	//
+	// This field is optional.
+	RateLimit float64
+
+	// RateLimitBurst is the maximum number of requests that can be sent at
+	// once before the RateLimit applies.
+	//
+	// If not set, the default value is 1.
+	//
+	// This field is optional.
+	RateLimitBurst int
+
+	// MaxResponseSize is the maximum size, in bytes, of a response body read
+	// by the client. Larger responses fail with ErrResponseTooLarge, which
+	// protects workers from oversized exports and snapshots.
+	//
+	// If not set, response bodies are not limited.
+	//
+	// This field is optional.
+	MaxResponseSize int64
+
+	// Decompressors adds content codings the client accepts when Compression
+	// is enabled, keyed by name, such as "zstd" or "br". An entry for "gzip"
+	// replaces the built-in gzip decompressor.
+	//
+	// This field is optional.
+	Decompressors map[string]Decompressor
+
+	// Cache stores the responses to GET requests, such as blueprints, and
+	// revalidates them with conditional requests: unchanged responses are
+	// answered with 304 Not Modified and served from the cache instead of
+	// being downloaded again. See MemoryCache and DiskCache.
+	//
+	// If not set, responses are not cached.
+	//
+	// This field is optional.
+	Cache Cache
+
+	// Tracer creates a span for every call made to the Cloudcraft API, with a
+	// child span per attempt, and propagates them to the API through the
+	// request headers.
+	//
+	// If not set, calls are not traced.
+	//
+	// This field is optional.
+	Tracer Tracer
+
+	// Metrics records the latency, retries, bytes sent and received and final
+	// status of every call made to the Cloudcraft API, labeled by service and
+	// operation. See InMemoryMetrics for a ready-made implementation.
+	//
+	// If not set, no metrics are recorded.
+	//
+	// This field is optional.
+	Metrics Metrics
+
+	// Logger receives the events of the client: requests, responses, retries
+	// and backoffs at the debug and info levels, and failed calls at the warn
+	// level. The API key and sensitive headers such as Authorization are
+	// always redacted.
+	//
+	// If not set, nothing is logged.
+	//
+	// This field is optional.
+	Logger *slog.Logger
+
+	// DebugWriter receives the wire dumps written when Debug is enabled.
+	//
+	// If not set, the default value is os.Stderr.
+	//
+	// This field is optional.
+	DebugWriter io.Writer
+
+	// DebugBodyLimit is the maximum number of bytes of a request or response
+	// body printed in a wire dump. Longer bodies are truncated.
+	//
+	// If not set, the default value is 4096.
+	//
+	// This field is optional.
+	DebugBodyLimit int
+
+	// Compression enables compressed responses. When set, the client asks the
+	// API for gzip-compressed responses and decompresses them transparently.
+	// The MaxResponseSize applies to the decompressed body.
+	//
+	// If not set, responses are not compressed.
+	//
+	// This field is optional.
+	Compression bool
+
+	// Coalesce enables request coalescing: identical GET requests made
+	// concurrently with the same credentials, such as several goroutines
+	// getting the same blueprint, share a single call to the API. Every
+	// caller gets its own copy of the response, so results can be modified
+	// safely.
+	//
+	// Shared calls are logged, traced and measured once, for the caller that
+	// made them. Calls made with a context carrying request options, such as
+	// WithTimeout or WithHeader, are never shared.
+	//
+	// If not set, the default value is false.
+	//
+	// This field is optional.
+	Coalesce bool
+
+	// Debug enables wire dumps: every request sent to the Cloudcraft API and
+	// every response received, retries included, is written to DebugWriter
+	// with its method, URL, headers and body, as sent after middleware and
+	// as received before decompression. The API key, sensitive headers such
+	// as cookies and tokens, and the secrets of accounts are masked, and
+	// binary or compressed bodies such as images and PDFs are summarized
+	// rather than printed.
+	//
+	// If not set, the value of the CLOUDCRAFT_DEBUG environment variable is
+	// used. If the environment variable is not set, the default value is
+	// false.
+	//
+	// This field is optional.
+	Debug bool
 }
 
 // NewConfig returns a new Config with the given API key.
@@ -147,35 +389,221 @@ func NewConfig(key string) *Config {
 }
 
 // NewConfigFromEnv returns a new Config from values set in the environment.
+//
+// If CLOUDCRAFT_API_KEY is not set but CLOUDCRAFT_API_KEY_FILE is, the API key
+// is read from that file by a FileCredentials, so that it can be rotated.
+//
+// NewConfigFromEnv ignores profiles; see NewConfigFromProfile.
 func NewConfigFromEnv() *Config {
-	return &Config{
-		Scheme:     xos.GetEnv(EnvScheme, DefaultScheme),
-		Host:       xos.GetEnv(EnvHost, DefaultHost),
-		Port:       xos.GetEnv(EnvPort, DefaultPort),
-		Path:       xos.GetEnv(EnvPath, DefaultPath),
-		MaxRetries: xos.GetIntEnv(EnvMaxRetries, DefaultMaxRetries),
-		Key:        xos.GetEnv(EnvAPIKey, ""),
-		Timeout:    xos.GetDurationEnv(EnvTimeout, DefaultTimeout),
+	cfg := NewConfig("")
+	cfg.applyEnv()
+
+	return cfg
+}
+
+// applyEnv overrides the fields of the Config with the values set in the
+// environment.
+//
+// The separate parts of the endpoint set in the environment take precedence
+// over an Endpoint set before, such as by a profile.
+func (c *Config) applyEnv() {
+	for _, key := range []string{EnvScheme, EnvHost, EnvPort, EnvPath} {
+		if xos.GetEnv(key, "") != "" {
+			c.Endpoint = ""
+		}
+	}
+
+	c.Scheme = xos.GetEnv(EnvScheme, c.Scheme)
+	c.Host = xos.GetEnv(EnvHost, c.Host)
+	c.Port = xos.GetEnv(EnvPort, c.Port)
+	c.Path = xos.GetEnv(EnvPath, c.Path)
+	c.Endpoint = xos.GetEnv(EnvEndpoint, c.Endpoint)
+	c.MaxRetries = xos.GetIntEnv(EnvMaxRetries, c.MaxRetries)
+	c.Timeout = xos.GetDurationEnv(EnvTimeout, c.Timeout)
+	c.Debug = xos.GetBoolEnv(EnvDebug, c.Debug)
+
+	if key := xos.GetEnv(EnvAPIKey, ""); key != "" {
+		c.Key, c.Credentials = key, nil
+	} else if path := xos.GetEnv(EnvAPIKeyFile, ""); path != "" {
+		c.Key, c.Credentials = "", NewFileCredentials(path)
 	}
 }
 
 // Validate checks that the Config is valid.
 func (c *Config) Validate() error {
-	if c.Scheme == "" {
+	if c.Endpoint == "" && c.Scheme == "" {
 		return ErrMissingEndpointScheme
 	}
 
-	if c.Host == "" {
+	if c.Endpoint == "" && c.Host == "" {
 		return ErrMissingEndpointHost
 	}
 
-	if c.Key == "" {
-		return ErrMissingKey
+	if _, err := c.endpoints(); err != nil {
+		return err
+	}
+
+	if c.Credentials == nil {
+		if err := validateKey(c.Key); err != nil {
+			return err
+		}
 	}
 
-	if len(c.Key) != 44 {
-		return ErrInvalidKey
+	if c.RateLimit < 0 || c.RateLimitBurst < 0 {
+		return ErrInvalidRateLimit
+	}
+
+	if c.MaxResponseSize < 0 {
+		return ErrInvalidMaxResponseSize
+	}
+
+	if c.RetryPolicy != nil {
+		if err := c.RetryPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.TLS != nil {
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.RetryBudget != nil {
+		if err := c.RetryBudget.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.CircuitBreaker != nil {
+		if err := c.CircuitBreaker.Validate(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// plainConfig has the fields of Config without its methods, to format it
+// without recursion.
+type plainConfig Config
+
+// LogValue implements the slog.LogValuer interface. The API key and the TLS
+// client key are redacted, and fields holding implementations of interfaces
+// or functions are logged by type.
+func (c Config) LogValue() slog.Value { //nolint:gocritic // Value receiver so that values are redacted too.
+	var (
+		retryPolicy    = slog.AnyValue(nil)
+		retryBudget    = slog.AnyValue(nil)
+		circuitBreaker = slog.AnyValue(nil)
+		tlsConfig      = slog.AnyValue(nil)
+	)
+
+	if c.RetryPolicy != nil {
+		retryPolicy = slog.GroupValue(
+			slog.String("isRetryable", typeName(c.RetryPolicy.IsRetryable)),
+			slog.String("jitter", typeName(c.RetryPolicy.Jitter)),
+			slog.Duration("minRetryDelay", c.RetryPolicy.MinRetryDelay),
+			slog.Duration("maxRetryDelay", c.RetryPolicy.MaxRetryDelay),
+			slog.Duration("maxRetryAfter", c.RetryPolicy.MaxRetryAfter),
+		)
+	}
+
+	if c.RetryBudget != nil {
+		retryBudget = slog.GroupValue(
+			slog.Float64("maxTokens", c.RetryBudget.MaxTokens),
+			slog.Float64("tokenRatio", c.RetryBudget.TokenRatio),
+		)
+	}
+
+	if c.CircuitBreaker != nil {
+		circuitBreaker = slog.GroupValue(
+			slog.String("onStateChange", typeName(c.CircuitBreaker.OnStateChange)),
+			slog.Float64("failureRate", c.CircuitBreaker.FailureRate),
+			slog.Int("window", c.CircuitBreaker.Window),
+			slog.Int("minRequests", c.CircuitBreaker.MinRequests),
+			slog.Duration("cooldown", c.CircuitBreaker.Cooldown),
+			slog.Int("probes", c.CircuitBreaker.Probes),
+		)
+	}
+
+	if c.TLS != nil {
+		tlsConfig = slog.GroupValue(
+			slog.Int("rootCAs", len(c.TLS.RootCAs)),
+			slog.Int("clientCertificate", len(c.TLS.ClientCertificate)),
+			slog.String("clientKey", redact(string(c.TLS.ClientKey))),
+			slog.String("rootCAFile", c.TLS.RootCAFile),
+			slog.String("clientCertificateFile", c.TLS.ClientCertificateFile),
+			slog.String("clientKeyFile", c.TLS.ClientKeyFile),
+			slog.String("serverName", c.TLS.ServerName),
+			slog.Uint64("minVersion", uint64(c.TLS.MinVersion)),
+		)
+	}
+
+	decompressors := make([]string, 0, len(c.Decompressors))
+	for encoding := range c.Decompressors {
+		decompressors = append(decompressors, encoding)
+	}
+
+	slices.Sort(decompressors)
+
+	return slog.GroupValue(
+		slog.String("scheme", c.Scheme),
+		slog.String("host", c.Host),
+		slog.String("port", c.Port),
+		slog.String("path", c.Path),
+		slog.String("endpoint", c.Endpoint),
+		slog.String("key", redact(c.Key)),
+		slog.String("credentials", typeName(c.Credentials)),
+		slog.Int("maxRetries", c.MaxRetries),
+		slog.Duration("timeout", c.Timeout),
+		slog.String("httpClient", typeName(c.HTTPClient)),
+		slog.Attr{Key: "retryPolicy", Value: retryPolicy},
+		slog.Attr{Key: "retryBudget", Value: retryBudget},
+		slog.String("transport", typeName(c.Transport)),
+		slog.Any("fallbackEndpoints", c.FallbackEndpoints),
+		slog.Duration("endpointCooldown", c.EndpointCooldown),
+		slog.Attr{Key: "circuitBreaker", Value: circuitBreaker},
+		slog.Attr{Key: "tls", Value: tlsConfig},
+		slog.Int("middleware", len(c.Middleware)),
+		slog.Float64("rateLimit", c.RateLimit),
+		slog.Int("rateLimitBurst", c.RateLimitBurst),
+		slog.Int64("maxResponseSize", c.MaxResponseSize),
+		slog.Any("decompressors", decompressors),
+		slog.String("cache", typeName(c.Cache)),
+		slog.String("tracer", typeName(c.Tracer)),
+		slog.String("metrics", typeName(c.Metrics)),
+		slog.String("logger", typeName(c.Logger)),
+		slog.String("debugWriter", typeName(c.DebugWriter)),
+		slog.Int("debugBodyLimit", c.DebugBodyLimit),
+		slog.Bool("compression", c.Compression),
+		slog.Bool("coalesce", c.Coalesce),
+		slog.Bool("debug", c.Debug),
+	)
+}
+
+// Format implements the fmt.Formatter interface. The API key is redacted.
+func (c Config) Format(f fmt.State, verb rune) { //nolint:gocritic // Value receiver so that values are redacted too.
+	plain := plainConfig(c)
+	plain.Key = redact(plain.Key)
+
+	fmt.Fprintf(f, fmt.FormatString(f, verb), plain)
+}
+
+// typeName returns the type of v, or an empty string if v is nil.
+func typeName(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	value := reflect.ValueOf(v)
+
+	switch value.Kind() { //nolint:exhaustive // Only nillable kinds are relevant.
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		if value.IsNil() {
+			return ""
+		}
+	}
+
+	return value.Type().String()
+}
@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+)
+
+// ErrCredentialsUnavailable is returned when a CredentialProvider fails to
+// provide an API key.
+const ErrCredentialsUnavailable xerrors.Error = "failed to get API key"
+
+// DefaultCommandCredentialsTTL is the default duration for which a key printed
+// by a command is used before the command is run again.
+const DefaultCommandCredentialsTTL time.Duration = 5 * time.Minute
+
+// keyLength is the length of a valid Cloudcraft API key.
+const keyLength int = 44
+
+// CredentialProvider provides the API key used to authenticate requests to the
+// Cloudcraft API. It is asked for a key every time a request is built, which
+// lets long-running programs pick up rotated keys without a restart.
+//
+// Implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	// Key returns the API key to use for a request.
+	Key(ctx context.Context) (string, error)
+}
+
+var (
+	_ CredentialProvider = (*StaticCredentials)(nil)
+	_ CredentialProvider = (*EnvCredentials)(nil)
+	_ CredentialProvider = (*FileCredentials)(nil)
+	_ CredentialProvider = (*CommandCredentials)(nil)
+)
+
+// validateKey checks that key looks like a valid API key.
+func validateKey(key string) error {
+	if key == "" {
+		return ErrMissingKey
+	}
+
+	if len(key) != keyLength {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
+
+// StaticCredentials is a CredentialProvider that returns a fixed API key,
+// which can be replaced at any time with SetKey.
+type StaticCredentials struct {
+	key atomic.Pointer[string]
+}
+
+// NewStaticCredentials returns a new StaticCredentials with the given key.
+func NewStaticCredentials(key string) *StaticCredentials {
+	credentials := &StaticCredentials{}
+	credentials.key.Store(&key)
+
+	return credentials
+}
+
+// Key implements the CredentialProvider interface.
+func (s *StaticCredentials) Key(_ context.Context) (string, error) {
+	return *s.key.Load(), nil
+}
+
+// SetKey replaces the key returned by the provider. Requests built after
+// SetKey returns use the new key. The key is not replaced if it is invalid.
+func (s *StaticCredentials) SetKey(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	s.key.Store(&key)
+
+	return nil
+}
+
+// EnvCredentials is a CredentialProvider that reads the API key from the
+// CLOUDCRAFT_API_KEY environment variable every time it is asked for one.
+type EnvCredentials struct{}
+
+// NewEnvCredentials returns a new EnvCredentials.
+func NewEnvCredentials() *EnvCredentials {
+	return &EnvCredentials{}
+}
+
+// Key implements the CredentialProvider interface.
+func (*EnvCredentials) Key(_ context.Context) (string, error) {
+	key := os.Getenv(EnvAPIKey)
+	if key == "" {
+		return "", fmt.Errorf("%w: %s is not set", ErrMissingKey, EnvAPIKey)
+	}
+
+	return key, nil
+}
+
+// FileCredentials is a CredentialProvider that reads the API key from a file.
+// The file is read again whenever its size or modification time changes, so
+// a key rotated by rewriting the file is picked up by the next request.
+//
+// Leading and trailing white space in the file is ignored.
+type FileCredentials struct {
+	modTime time.Time
+	path    string
+	key     string
+	size    int64
+	mu      sync.Mutex
+}
+
+// NewFileCredentials returns a new FileCredentials reading the key from the
+// file at path.
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+// Key implements the CredentialProvider interface.
+func (f *FileCredentials) Key(_ context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	if f.key != "" && info.Size() == f.size && info.ModTime().Equal(f.modTime) {
+		return f.key, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("%w: %s is empty", ErrMissingKey, f.path)
+	}
+
+	f.key, f.size, f.modTime = key, info.Size(), info.ModTime()
+
+	return f.key, nil
+}
+
+// CommandCredentials is a CredentialProvider that runs an external command,
+// such as the CLI of a secret manager, and uses what it prints to its standard
+// output as the API key. The key is cached for a TTL, after which the command
+// is run again.
+//
+// Leading and trailing white space in the output is ignored.
+type CommandCredentials struct {
+	expires time.Time
+	name    string
+	key     string
+	args    []string
+	ttl     time.Duration
+	mu      sync.Mutex
+}
+
+// NewCommandCredentials returns a new CommandCredentials that runs the command
+// with the given name and arguments, and caches the key it prints for ttl. If
+// ttl is zero or negative, DefaultCommandCredentialsTTL is used.
+func NewCommandCredentials(ttl time.Duration, name string, args ...string) *CommandCredentials {
+	if ttl <= 0 {
+		ttl = DefaultCommandCredentialsTTL
+	}
+
+	return &CommandCredentials{
+		name: name,
+		args: args,
+		ttl:  ttl,
+	}
+}
+
+// Key implements the CredentialProvider interface. Concurrent calls wait for
+// a single run of the command.
+func (c *CommandCredentials) Key(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.key != "" && time.Now().Before(c.expires) {
+		return c.key, nil
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, c.name, c.args...) //nolint:gosec // The command is chosen by the program, not by its input.
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	key := strings.TrimSpace(string(output))
+	if key == "" {
+		return "", fmt.Errorf("%w: %s printed nothing", ErrMissingKey, c.name)
+	}
+
+	c.key, c.expires = key, time.Now().Add(c.ttl)
+
+	return c.key, nil
+}
+
+// SetCredentials replaces the CredentialProvider of the client. It is safe to
+// call while requests are in progress: requests built after SetCredentials
+// returns use the new provider. A nil provider is ignored.
+func (c *Client) SetCredentials(provider CredentialProvider) {
+	if provider == nil {
+		return
+	}
+
+	c.credentials.Store(&provider)
+}
+
+// key returns the API key to authenticate a request with: the one set with
+// WithKey, if any, or else the one given by the credentials of the client.
+func (c *Client) key(ctx context.Context) (string, error) {
+	key := requestOptionsFrom(ctx).key
+
+	if key == "" {
+		var err error
+
+		provider := *c.credentials.Load()
+
+		if key, err = provider.Key(ctx); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrCredentialsUnavailable, err)
+		}
+	}
+
+	if err := validateKey(key); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrCredentialsUnavailable, err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+const (
+	_testRotatedKeyA = "rotated-key-a-aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	_testRotatedKeyB = "rotated-key-b-bbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func TestCredentialProviders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("Static credentials", func(t *testing.T) {
+		t.Parallel()
+
+		credentials := cloudcraft.NewStaticCredentials(_testRotatedKeyA)
+
+		if err := credentials.SetKey("short_key"); !errors.Is(err, cloudcraft.ErrInvalidKey) {
+			t.Fatalf("SetKey() error = %v, want %v", err, cloudcraft.ErrInvalidKey)
+		}
+
+		if err := credentials.SetKey(_testRotatedKeyB); err != nil {
+			t.Fatalf("SetKey() error = %v", err)
+		}
+
+		if key, _ := credentials.Key(ctx); key != _testRotatedKeyB {
+			t.Fatalf("Key() = %q, want %q", key, _testRotatedKeyB)
+		}
+	})
+
+	t.Run("File credentials", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "key")
+		credentials := cloudcraft.NewFileCredentials(path)
+
+		if _, err := credentials.Key(ctx); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("Key() error = %v, want %v", err, os.ErrNotExist)
+		}
+
+		writeKeyFile(t, path, _testRotatedKeyA+"\n", time.Now().Add(-time.Hour))
+
+		if key, _ := credentials.Key(ctx); key != _testRotatedKeyA {
+			t.Fatalf("Key() = %q, want %q", key, _testRotatedKeyA)
+		}
+
+		writeKeyFile(t, path, _testRotatedKeyB+"\n", time.Now())
+
+		if key, _ := credentials.Key(ctx); key != _testRotatedKeyB {
+			t.Fatalf("Key() after rotation = %q, want %q", key, _testRotatedKeyB)
+		}
+	})
+
+	t.Run("Command credentials", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := exec.LookPath("echo"); err != nil {
+			t.Skip("echo is not available")
+		}
+
+		credentials := cloudcraft.NewCommandCredentials(time.Hour, "echo", _testRotatedKeyA)
+
+		if key, err := credentials.Key(ctx); err != nil || key != _testRotatedKeyA {
+			t.Fatalf("Key() = %q, %v, want %q", key, err, _testRotatedKeyA)
+		}
+
+		failing := cloudcraft.NewCommandCredentials(0, filepath.Join(t.TempDir(), "missing"))
+
+		if _, err := failing.Key(ctx); err == nil {
+			t.Fatal("Key() error = nil, want an error")
+		}
+	})
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv(cloudcraft.EnvAPIKey, _testRotatedKeyA)
+
+	credentials := cloudcraft.NewEnvCredentials()
+
+	if key, _ := credentials.Key(context.Background()); key != _testRotatedKeyA {
+		t.Fatalf("Key() = %q, want %q", key, _testRotatedKeyA)
+	}
+
+	t.Setenv(cloudcraft.EnvAPIKey, "")
+
+	if _, err := credentials.Key(context.Background()); !errors.Is(err, cloudcraft.ErrMissingKey) {
+		t.Fatalf("Key() error = %v, want %v", err, cloudcraft.ErrMissingKey)
+	}
+}
+
+func TestNewConfigFromEnv_KeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	writeKeyFile(t, path, _testRotatedKeyA, time.Now())
+
+	t.Setenv(cloudcraft.EnvAPIKey, "")
+	t.Setenv(cloudcraft.EnvAPIKeyFile, path)
+
+	cfg := cloudcraft.NewConfigFromEnv()
+	if cfg.Credentials == nil {
+		t.Fatal("NewConfigFromEnv() did not set Credentials")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if key, _ := cfg.Credentials.Key(context.Background()); key != _testRotatedKeyA {
+		t.Fatalf("Key() = %q, want %q", key, _testRotatedKeyA)
+	}
+}
+
+func TestClient_SetCredentials(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		case _testRotatedKeyA, _testRotatedKeyB:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentials := cloudcraft.NewStaticCredentials(_testRotatedKeyA)
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		cfg.Credentials = credentials
+	})
+
+	var (
+		ctx = context.Background()
+		wg  sync.WaitGroup
+	)
+
+	// Keys are rotated while requests are in progress, which must neither
+	// race nor make any request use a stale or partial key.
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				if _, _, err := client.User.Me(ctx); err != nil {
+					t.Errorf("User.Me() error = %v", err)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		key := _testRotatedKeyA
+		if i%2 == 0 {
+			key = _testRotatedKeyB
+		}
+
+		if err = credentials.SetKey(key); err != nil {
+			t.Fatalf("SetKey() error = %v", err)
+		}
+	}
+
+	client.SetCredentials(cloudcraft.NewStaticCredentials(_testRotatedKeyB))
+
+	wg.Wait()
+
+	client.SetCredentials(cloudcraft.NewStaticCredentials("short_key"))
+
+	if _, _, err = client.User.Me(ctx); !errors.Is(err, cloudcraft.ErrCredentialsUnavailable) {
+		t.Fatalf("User.Me() error = %v, want %v", err, cloudcraft.ErrCredentialsUnavailable)
+	}
+}
+
+// writeKeyFile writes key to the file at path and sets its modification time.
+func writeKeyFile(t *testing.T, path, key string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/xos"
+)
+
+// DefaultDebugBodyLimit is the default maximum number of bytes of a request or
+// response body printed in a wire dump.
+const DefaultDebugBodyLimit int = 4096
+
+// secretFieldPattern matches the JSON fields of request and response bodies
+// that hold secrets, including values cut short by truncation.
+var secretFieldPattern = regexp.MustCompile(`"(clientSecret|externalId)"\s*:\s*"(?:[^"\\]|\\.)*(?:"|$)`)
+
+// dumpTransport is an http.RoundTripper that writes every request it sends and
+// every response it receives to a writer, to capture what went over the wire.
+//
+// It sits below the middleware and above the decoding of response bodies, so
+// headers set by middleware are dumped, redacted like those of the client, and
+// compressed bodies are summarized rather than printed.
+type dumpTransport struct {
+	next  http.RoundTripper
+	w     io.Writer
+	mu    sync.Mutex
+	seq   atomic.Uint64
+	limit int
+}
+
+var _ http.RoundTripper = (*dumpTransport)(nil)
+
+// debugEnabled reports whether wire dumps are enabled by cfg or by the
+// environment.
+func debugEnabled(cfg *Config) bool {
+	return cfg.Debug || xos.GetBoolEnv(EnvDebug, false)
+}
+
+// newDumpTransport returns a dumpTransport that sends requests with next and
+// dumps them as configured by cfg.
+func newDumpTransport(next http.RoundTripper, cfg *Config) *dumpTransport {
+	transport := &dumpTransport{
+		next:  next,
+		w:     cfg.DebugWriter,
+		limit: cfg.DebugBodyLimit,
+	}
+
+	if transport.w == nil {
+		transport.w = os.Stderr
+	}
+
+	if transport.limit <= 0 {
+		transport.limit = DefaultDebugBodyLimit
+	}
+
+	return transport
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	seq := t.seq.Add(1)
+
+	var dump bytes.Buffer
+
+	fmt.Fprintf(&dump, "> #%d %s %s %s\n", seq, req.Method, req.URL.String(), req.Proto)
+	dumpHeader(&dump, "> ", redactHeader(req.Header))
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := t.dumpBody(&dump, req.Header, req.Body, req.ContentLength)
+
+		req = req.Clone(req.Context())
+		req.Body = body
+
+		if err != nil {
+			t.write(&dump)
+
+			return nil, fmt.Errorf("%w", err)
+		}
+	}
+
+	t.write(&dump)
+
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(&dump, "< #%d error after %s: %v\n\n", seq, time.Since(start), err)
+		t.write(&dump)
+
+		return resp, fmt.Errorf("%w", err)
+	}
+
+	fmt.Fprintf(&dump, "< #%d %s %s (%s)\n", seq, resp.Proto, resp.Status, time.Since(start))
+	dumpHeader(&dump, "< ", redactHeader(resp.Header))
+
+	// A failure to read the body is dumped and left for the client to report
+	// when it reads the body itself.
+	resp.Body, _ = t.dumpBody(&dump, resp.Header, resp.Body, resp.ContentLength)
+
+	t.write(&dump)
+
+	return resp, nil
+}
+
+// dumpBody writes body to dump, truncated to the limit of the transport, or a
+// summary of it if it is binary. It returns a body that still yields all of
+// the data of the original one.
+func (t *dumpTransport) dumpBody(
+	dump *bytes.Buffer,
+	header http.Header,
+	body io.ReadCloser,
+	contentLength int64,
+) (io.ReadCloser, error) {
+	if kind, ok := binaryBody(header); ok {
+		dumpOmitted(dump, kind, contentLength)
+		dump.WriteByte('\n')
+
+		return body, nil
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(body, int64(t.limit)+1))
+
+	// Bodies that are not labeled as binary are sniffed before being dumped.
+	if kind := http.DetectContentType(peeked); !strings.HasPrefix(kind, "text/") {
+		dumpOmitted(dump, kind, contentLength)
+	} else {
+		t.dumpText(dump, peeked)
+	}
+
+	if err != nil {
+		fmt.Fprintf(dump, "[error reading body: %v]\n", err)
+	}
+
+	dump.WriteByte('\n')
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), body), body}, err
+}
+
+// dumpText writes the text body peeked to dump, truncated to the limit of the
+// transport and with its secrets redacted.
+func (t *dumpTransport) dumpText(dump *bytes.Buffer, peeked []byte) {
+	shown := peeked
+	if len(shown) > t.limit {
+		shown = shown[:t.limit]
+	}
+
+	dump.Write(secretFieldPattern.ReplaceAll(shown, []byte(`"$1":"`+redactedValue+`"`)))
+
+	if len(shown) > 0 {
+		dump.WriteByte('\n')
+	}
+
+	if len(peeked) > t.limit {
+		fmt.Fprintf(dump, "[body truncated to %d bytes]\n", t.limit)
+	}
+}
+
+// dumpOmitted writes to dump the summary of a binary body of the given kind
+// and content length, in place of the body.
+func dumpOmitted(dump *bytes.Buffer, kind string, contentLength int64) {
+	size := "unknown size"
+	if contentLength >= 0 {
+		size = fmt.Sprintf("%d bytes", contentLength)
+	}
+
+	fmt.Fprintf(dump, "[%s body omitted, %s]\n", kind, size)
+}
+
+// write writes dump to the writer of the transport at once, so that the dumps
+// of concurrent requests are not interleaved, and resets it.
+func (t *dumpTransport) write(dump *bytes.Buffer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, _ = t.w.Write(dump.Bytes())
+
+	dump.Reset()
+}
+
+// dumpHeader writes header to dump, one field per line in sorted order, each
+// line starting with prefix.
+func dumpHeader(dump *bytes.Buffer, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(dump, "%s%s: %s\n", prefix, key, value)
+		}
+	}
+
+	dump.WriteString(prefix + "\n")
+}
+
+// binaryBody reports whether a body with the given header is binary, such as
+// an image, a PDF, a spreadsheet or a compressed body, and returns a
+// description of it.
+func binaryBody(header http.Header) (string, bool) {
+	if encoding := header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		return encoding + "-encoded", true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return "", false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		mediaType == "application/pdf",
+		mediaType == "application/zip",
+		mediaType == "application/octet-stream":
+		return mediaType, true
+	case strings.HasPrefix(mediaType, "application/vnd."):
+		// Vendor types are binary, such as XLSX budget exports, unless they
+		// use a text syntax.
+		textual := strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+
+		return mediaType, !textual
+	default:
+		return "", false
+	}
+}
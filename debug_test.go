@@ -0,0 +1,214 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+// _testSessionCookie is a session cookie set by the mock API.
+const _testSessionCookie string = "s3cr3t-53ss10n"
+
+func TestClient_Debug(t *testing.T) {
+	t.Parallel()
+
+	var (
+		createData   = xtesting.ReadFile(t, filepath.Join(_testAzureDataPath, "create-valid.json"))
+		snapshotData = xtesting.ReadFile(t, filepath.Join(_testAzureDataPath, "snapshot-valid.png"))
+		largeBody    = `{"name":"` + strings.Repeat("a", 100) + `"}`
+		budgetData   = newSpreadsheet(t)
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.Header().Set("Set-Cookie", "session="+_testSessionCookie)
+			w.WriteHeader(http.StatusOK)
+			w.Write(createData)
+		case strings.HasSuffix(r.URL.Path, "/png"):
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Length", strconv.Itoa(len(snapshotData)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(snapshotData)
+		case strings.HasSuffix(r.URL.Path, "/budget/xlsx"):
+			w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+			w.Header().Set("Content-Length", strconv.Itoa(len(budgetData)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(budgetData)
+		case strings.HasSuffix(r.URL.Path, "/budget/xls"):
+			w.Header().Set("Content-Type", "application/x-msexcel")
+			w.WriteHeader(http.StatusOK)
+			w.Write(budgetData)
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(largeBody))
+		}
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output syncBuffer
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		cfg.Debug = true
+		cfg.DebugWriter = &output
+		cfg.DebugBodyLimit = 64
+		cfg.Middleware = []cloudcraft.Middleware{
+			func(next http.RoundTripper) http.RoundTripper {
+				return cloudcraft.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					req = req.Clone(req.Context())
+					req.Header.Set("X-Api-Key", _testAPIKey)
+					req.Header.Set("Idempotency-Key", "7d9f2c1e4b6a8d0f")
+
+					return next.RoundTrip(req)
+				})
+			},
+		}
+	})
+
+	ctx := context.Background()
+
+	if _, _, err = client.Azure.Create(ctx, &cloudcraft.AzureAccount{
+		Name:           "Go SDK Test",
+		ApplicationID:  "app-id",
+		DirectoryID:    "dir-id",
+		SubscriptionID: "sub-id",
+		ClientSecret:   _testClientSecret,
+	}); err != nil {
+		t.Fatalf("Azure.Create() error = %v", err)
+	}
+
+	snapshot, _, err := client.Azure.Snapshot(ctx, "account-id", "eastus", "png", nil)
+	if err != nil {
+		t.Fatalf("Azure.Snapshot() error = %v", err)
+	}
+
+	if !bytes.Equal(snapshot, snapshotData) {
+		t.Fatal("Azure.Snapshot() returned a body altered by the wire dump")
+	}
+
+	// Spreadsheets are binary whether or not their type says so.
+	for _, format := range []string{"xlsx", "xls"} {
+		budget, _, err := client.Blueprint.ExportBudget(ctx, "blueprint-id", format, nil)
+		if err != nil {
+			t.Fatalf("Blueprint.ExportBudget(%q) error = %v", format, err)
+		}
+
+		if !bytes.Equal(budget, budgetData) {
+			t.Fatalf("Blueprint.ExportBudget(%q) returned a body altered by the wire dump", format)
+		}
+	}
+
+	_, resp, err := client.User.Me(ctx)
+	if err != nil {
+		t.Fatalf("User.Me() error = %v", err)
+	}
+
+	// Bodies are received in full even though their dump is truncated.
+	if string(resp.Body) != largeBody {
+		t.Fatalf("User.Me() body = %q, want %q", resp.Body, largeBody)
+	}
+
+	dump := output.String()
+
+	for _, want := range []string{
+		"> #1 POST " + ts.URL + "/azure/account HTTP/1.1\n",
+		"> Authorization: Bearer [REDACTED]\n",
+		"> X-Api-Key: [REDACTED]\n",
+		"> Idempotency-Key: 7d9f2c1e4b6a8d0f\n",
+		"< Set-Cookie: [REDACTED]\n",
+		`"clientSecret":"[REDACTED]"`,
+		"< #1 HTTP/1.1 200 OK (",
+		"> #2 GET " + ts.URL + "/azure/account/account-id/eastus/png",
+		"[image/png body omitted, " + strconv.Itoa(len(snapshotData)) + " bytes]\n",
+		"[application/vnd.openxmlformats-officedocument.spreadsheetml.sheet body omitted, " +
+			strconv.Itoa(len(budgetData)) + " bytes]\n",
+		"[application/zip body omitted, " + strconv.Itoa(len(budgetData)) + " bytes]\n",
+		"[body truncated to 64 bytes]\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump does not contain %q:\n%s", want, dump)
+		}
+	}
+
+	if strings.Contains(dump, "PK\x03\x04") {
+		t.Fatalf("dump contains a binary body:\n%s", dump)
+	}
+
+	for _, secret := range []string{_testAPIKey, _testClientSecret, _testSessionCookie} {
+		if strings.Contains(dump, secret) {
+			t.Fatalf("dump contains a secret:\n%s", dump)
+		}
+	}
+}
+
+// newSpreadsheet returns a minimal ZIP archive standing in for an XLSX file.
+func newSpreadsheet(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+
+	file, err := archive.Create("xl/workbook.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = file.Write([]byte(`<workbook/>`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestClient_Debug_Env(t *testing.T) {
+	t.Setenv(cloudcraft.EnvDebug, "true")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output syncBuffer
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		cfg.DebugWriter = &output
+	})
+
+	_, _, err = client.Blueprint.Get(context.Background(), "blueprint-id")
+	if !cloudcraft.IsNotFound(err) {
+		t.Fatalf("Blueprint.Get() error = %v, want a not found error", err)
+	}
+
+	if dump := output.String(); !strings.Contains(dump, "< #1 HTTP/1.1 404 Not Found (") {
+		t.Fatalf("dump = %q, want the response to be dumped", dump)
+	}
+}
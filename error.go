@@ -4,7 +4,15 @@
 
 package cloudcraft
 
-import "github.com/DataDog/cloudcraft-go/internal/xerrors"
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+)
 
 const (
 	// ErrNilContext is returned when a nil context is passed to a function.
@@ -27,4 +35,212 @@ const (
 
 	// ErrEmptyRegion is returned when an empty region is passed as an argument.
 	ErrEmptyRegion xerrors.Error = "region cannot be empty"
+
+	// ErrNilWriter is returned when a nil io.Writer is passed as an argument.
+	ErrNilWriter xerrors.Error = "writer cannot be nil"
 )
+
+// Classes of API errors. An APIError matches, through errors.Is, the class that
+// corresponds to its status code.
+const (
+	// ErrNotFound is matched by API errors caused by a missing resource, such
+	// as a blueprint or an account that does not exist (404).
+	ErrNotFound xerrors.Error = "resource not found"
+
+	// ErrConflict is matched by API errors caused by a conflicting update, such
+	// as an ETag precondition failure when updating a blueprint (409, 412).
+	ErrConflict xerrors.Error = "resource conflict"
+
+	// ErrRateLimited is matched by API errors caused by too many requests
+	// being sent to the API (429).
+	ErrRateLimited xerrors.Error = "rate limited"
+
+	// ErrUnauthorized is matched by API errors caused by a missing, invalid or
+	// insufficiently privileged API key (401, 403).
+	ErrUnauthorized xerrors.Error = "unauthorized"
+
+	// ErrServerError is matched by API errors caused by a failure on the side
+	// of the Cloudcraft API (5xx).
+	ErrServerError xerrors.Error = "server error"
+)
+
+// maxErrorBodySize is the maximum number of bytes read from the body of an
+// error response returned by the Cloudcraft API.
+const maxErrorBodySize int64 = 1 << 20
+
+// requestIDHeaders lists the response headers that may carry the ID assigned
+// to a request by the Cloudcraft API or the infrastructure in front of it, in
+// order of preference.
+var requestIDHeaders = [...]string{ //nolint:gochecknoglobals // read-only lookup table
+	"X-Request-Id",
+	"X-Amzn-Requestid",
+	"X-Amz-Request-Id",
+	"X-Amz-Cf-Id",
+}
+
+// ErrorPayload represents the body of an error response returned by the
+// Cloudcraft API.
+type ErrorPayload struct {
+	// Error is a short description of the error, usually the status text.
+	Error string `json:"error,omitempty"`
+
+	// Message is a human-readable explanation of what went wrong.
+	Message string `json:"message,omitempty"`
+
+	// StatusCode is the HTTP status code reported by the API in the payload.
+	StatusCode int `json:"statusCode,omitempty"`
+}
+
+// APIError is returned when the Cloudcraft API responds to a request with a
+// status code that indicates a failure.
+//
+// APIError wraps ErrRequestFailed, so callers can keep using errors.Is to
+// detect failed requests and errors.As to inspect the details.
+type APIError struct {
+	// Header contains the response headers.
+	Header http.Header
+
+	// Payload contains the decoded error payload returned by the API. It is
+	// nil if the response body is empty or is not valid JSON.
+	Payload *ErrorPayload
+
+	// Method is the HTTP method of the request that failed.
+	Method string
+
+	// URL is the URL of the request that failed.
+	URL string
+
+	// RequestID is the ID assigned to the request by the API, if any.
+	RequestID string
+
+	// Body contains the raw response body, truncated to 1 MiB.
+	Body []byte
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+}
+
+// newAPIError returns a new APIError built from the given response and its
+// already-read body.
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		Header:     resp.Header,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Body:       body,
+		StatusCode: resp.StatusCode,
+	}
+
+	for _, name := range requestIDHeaders {
+		if id := resp.Header.Get(name); id != "" {
+			apiErr.RequestID = id
+
+			break
+		}
+	}
+
+	if len(body) > 0 {
+		var payload ErrorPayload
+
+		if err := json.Unmarshal(body, &payload); err == nil {
+			apiErr.Payload = &payload
+		}
+	}
+
+	return apiErr
+}
+
+// Error implements the error interface for APIError.
+func (e *APIError) Error() string {
+	var builder strings.Builder
+
+	builder.WriteString(ErrRequestFailed.Error())
+	builder.WriteString(": ")
+	builder.WriteString(strconv.Itoa(e.StatusCode))
+
+	if e.Payload != nil {
+		if e.Payload.Error != "" {
+			builder.WriteString(": ")
+			builder.WriteString(e.Payload.Error)
+		}
+
+		if e.Payload.Message != "" {
+			builder.WriteString(": ")
+			builder.WriteString(e.Payload.Message)
+		}
+	}
+
+	if e.Method != "" || e.URL != "" {
+		builder.WriteString(" (")
+		builder.WriteString(e.Method)
+		builder.WriteByte(' ')
+		builder.WriteString(e.URL)
+
+		if e.RequestID != "" {
+			builder.WriteString(", request ID ")
+			builder.WriteString(e.RequestID)
+		}
+
+		builder.WriteByte(')')
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns ErrRequestFailed so that errors.Is(err, ErrRequestFailed)
+// reports true for any APIError.
+func (*APIError) Unwrap() error {
+	return ErrRequestFailed
+}
+
+// Is reports whether the APIError belongs to the class of errors represented
+// by target, one of ErrNotFound, ErrConflict, ErrRateLimited, ErrUnauthorized
+// or ErrServerError.
+func (e *APIError) Is(target error) bool {
+	switch target { //nolint:errorlint // comparing against sentinel errors
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict ||
+			e.StatusCode == http.StatusPreconditionFailed
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized ||
+			e.StatusCode == http.StatusForbidden
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// IsNotFound reports whether err was caused by the Cloudcraft API not finding
+// the requested resource.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict reports whether err was caused by a conflicting update, such as
+// an outdated ETag passed to BlueprintService.Update.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsRateLimited reports whether err was caused by the Cloudcraft API
+// throttling requests.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsUnauthorized reports whether err was caused by a missing, invalid or
+// insufficiently privileged API key.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsServerError reports whether err was caused by a failure on the side of
+// the Cloudcraft API.
+func IsServerError(err error) bool {
+	return errors.Is(err, ErrServerError)
+}
@@ -0,0 +1,219 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		handler       http.HandlerFunc
+		wantStatus    int
+		wantPayload   *cloudcraft.ErrorPayload
+		wantRequestID string
+	}{
+		{
+			name: "JSON error payload",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Request-Id", "f3b1c9a2")
+				w.WriteHeader(http.StatusForbidden)
+
+				w.Write([]byte(`{"statusCode":403,"error":"Forbidden","message":"Insufficient permissions"}`))
+			},
+			wantStatus: http.StatusForbidden,
+			wantPayload: &cloudcraft.ErrorPayload{
+				Error:      "Forbidden",
+				Message:    "Insufficient permissions",
+				StatusCode: http.StatusForbidden,
+			},
+			wantRequestID: "f3b1c9a2",
+		},
+		{
+			name: "Plain text error body",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+
+				w.Write([]byte(`unprocessable`))
+			},
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantPayload: nil,
+		},
+		{
+			name: "Empty error body",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantStatus:  http.StatusNotFound,
+			wantPayload: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			endpoint, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := xtesting.SetupMockClient(t, endpoint)
+
+			_, resp, err := client.Blueprint.Get(context.Background(), "0f1b2c3d")
+			if !errors.Is(err, cloudcraft.ErrRequestFailed) {
+				t.Fatalf("BlueprintService.Get() error = %v, want %v", err, cloudcraft.ErrRequestFailed)
+			}
+
+			var apiErr *cloudcraft.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("BlueprintService.Get() error = %T, want *cloudcraft.APIError", err)
+			}
+
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("APIError.StatusCode = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+			}
+
+			if apiErr.Method != http.MethodGet {
+				t.Errorf("APIError.Method = %q, want %q", apiErr.Method, http.MethodGet)
+			}
+
+			if wantURL := ts.URL + "/blueprint/0f1b2c3d"; apiErr.URL != wantURL {
+				t.Errorf("APIError.URL = %q, want %q", apiErr.URL, wantURL)
+			}
+
+			if apiErr.RequestID != tt.wantRequestID {
+				t.Errorf("APIError.RequestID = %q, want %q", apiErr.RequestID, tt.wantRequestID)
+			}
+
+			if (apiErr.Payload == nil) != (tt.wantPayload == nil) ||
+				(apiErr.Payload != nil && *apiErr.Payload != *tt.wantPayload) {
+				t.Errorf("APIError.Payload = %+v, want %+v", apiErr.Payload, tt.wantPayload)
+			}
+
+			if resp == nil || resp.Status != tt.wantStatus {
+				t.Errorf("BlueprintService.Get() response = %+v, want status %d", resp, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		give             error
+		wantNotFound     bool
+		wantConflict     bool
+		wantRateLimited  bool
+		wantUnauthorized bool
+		wantServerError  bool
+	}{
+		{
+			name:         "Not found",
+			give:         &cloudcraft.APIError{StatusCode: http.StatusNotFound},
+			wantNotFound: true,
+		},
+		{
+			name:         "Conflict",
+			give:         &cloudcraft.APIError{StatusCode: http.StatusConflict},
+			wantConflict: true,
+		},
+		{
+			name:         "Precondition failed",
+			give:         &cloudcraft.APIError{StatusCode: http.StatusPreconditionFailed},
+			wantConflict: true,
+		},
+		{
+			name:            "Too many requests",
+			give:            &cloudcraft.APIError{StatusCode: http.StatusTooManyRequests},
+			wantRateLimited: true,
+		},
+		{
+			name:             "Unauthorized",
+			give:             &cloudcraft.APIError{StatusCode: http.StatusUnauthorized},
+			wantUnauthorized: true,
+		},
+		{
+			name:             "Forbidden",
+			give:             &cloudcraft.APIError{StatusCode: http.StatusForbidden},
+			wantUnauthorized: true,
+		},
+		{
+			name:            "Internal server error",
+			give:            &cloudcraft.APIError{StatusCode: http.StatusInternalServerError},
+			wantServerError: true,
+		},
+		{
+			name:            "Wrapped service unavailable",
+			give:            fmt.Errorf("wrapped: %w", &cloudcraft.APIError{StatusCode: http.StatusServiceUnavailable}),
+			wantServerError: true,
+		},
+		{
+			name: "Bad request",
+			give: &cloudcraft.APIError{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name: "Not an API error",
+			give: errors.New("connection reset by peer"),
+		},
+		{
+			name: "Nil error",
+			give: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := cloudcraft.IsNotFound(tt.give); got != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v", got, tt.wantNotFound)
+			}
+
+			if got := cloudcraft.IsConflict(tt.give); got != tt.wantConflict {
+				t.Errorf("IsConflict() = %v, want %v", got, tt.wantConflict)
+			}
+
+			if got := cloudcraft.IsRateLimited(tt.give); got != tt.wantRateLimited {
+				t.Errorf("IsRateLimited() = %v, want %v", got, tt.wantRateLimited)
+			}
+
+			if got := cloudcraft.IsUnauthorized(tt.give); got != tt.wantUnauthorized {
+				t.Errorf("IsUnauthorized() = %v, want %v", got, tt.wantUnauthorized)
+			}
+
+			if got := cloudcraft.IsServerError(tt.give); got != tt.wantServerError {
+				t.Errorf("IsServerError() = %v, want %v", got, tt.wantServerError)
+			}
+
+			if tt.give != nil && errors.As(tt.give, new(*cloudcraft.APIError)) &&
+				!errors.Is(tt.give, cloudcraft.ErrRequestFailed) {
+				t.Error("errors.Is(err, ErrRequestFailed) = false, want true")
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/endpoint"
+)
+
+// DefaultEndpointCooldown is the default duration for which an endpoint that
+// failed is avoided when fallback endpoints are configured.
+const DefaultEndpointCooldown time.Duration = 30 * time.Second
+
+// EndpointHealth describes the health of an endpoint the client fails over
+// between.
+type EndpointHealth struct {
+	// DownUntil is the end of the cool-down period of the endpoint, during
+	// which the next healthy endpoint is preferred. It is zero if the
+	// endpoint is healthy.
+	DownUntil time.Time
+
+	// URL is the base URL of the endpoint.
+	URL string
+
+	// Failures is the number of consecutive failed attempts made to the
+	// endpoint.
+	Failures int
+
+	// Healthy reports whether the endpoint is out of its cool-down period.
+	Healthy bool
+}
+
+// endpoints returns the base URLs of the Cloudcraft API given by the Config,
+// in order of preference: the primary endpoint first, then the fallback ones.
+func (c *Config) endpoints() ([]*url.URL, error) {
+	var (
+		primary *url.URL
+		err     error
+	)
+
+	if c.Endpoint != "" {
+		primary, err = endpoint.ParseURL(c.Endpoint)
+	} else {
+		primary, err = endpoint.Parse(c.Scheme, c.Host, c.Port, c.Path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEndpoint, err)
+	}
+
+	endpoints := []*url.URL{primary}
+
+	for _, raw := range c.FallbackEndpoints {
+		fallback, err := endpoint.ParseURL(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidEndpoint, raw, err)
+		}
+
+		endpoints = append(endpoints, fallback)
+	}
+
+	return endpoints, nil
+}
+
+// newEndpointPool returns the pool of endpoints the client fails over between
+// given a Config, or nil if it has a single endpoint.
+func newEndpointPool(cfg *Config, endpoints []*url.URL) *endpoint.Pool {
+	if len(endpoints) < 2 {
+		return nil
+	}
+
+	cooldown := cfg.EndpointCooldown
+	if cooldown <= 0 {
+		cooldown = DefaultEndpointCooldown
+	}
+
+	return endpoint.NewPool(endpoints, cooldown)
+}
+
+// route sends req, built against the primary endpoint, to the preferred
+// healthy endpoint. It returns the routed request and the index of its
+// endpoint, to report the outcome of the attempt with observeEndpoint.
+func (c *Client) route(req *http.Request) (*http.Request, int) {
+	if c.endpoints == nil {
+		return req, 0
+	}
+
+	index, base := c.endpoints.Pick()
+	if index == 0 {
+		return req, index
+	}
+
+	routed := req.WithContext(req.Context())
+	routed.URL = endpoint.Rebase(req.URL, c.cfg.endpoint, base)
+	routed.Host = base.Host
+
+	return routed, index
+}
+
+// observeEndpoint records the outcome of an attempt sent to the endpoint at
+// index. Connection errors and server errors put the endpoint in cool-down.
+func (c *Client) observeEndpoint(req *http.Request, index int, resp *http.Response, err error) {
+	if c.endpoints == nil || req.Context().Err() != nil {
+		return
+	}
+
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		c.endpoints.MarkFailure(index)
+
+		return
+	}
+
+	c.endpoints.MarkSuccess(index)
+}
+
+// failingOver reports whether the next attempt of a request whose last attempt
+// was sent to the endpoint at index goes to another, healthy endpoint. When
+// every endpoint is down, the next attempt waits for the backoff even though
+// it may go to another endpoint.
+func (c *Client) failingOver(index int) bool {
+	if c.endpoints == nil {
+		return false
+	}
+
+	next, _ := c.endpoints.Pick()
+
+	return next != index && c.endpoints.Healthy(next)
+}
+
+// EndpointHealth returns the health of the endpoints the client fails over
+// between, in order of preference. It returns nil if no fallback endpoints
+// are configured.
+func (c *Client) EndpointHealth() []EndpointHealth {
+	if c.endpoints == nil {
+		return nil
+	}
+
+	var (
+		pool   = c.endpoints.Health()
+		health = make([]EndpointHealth, len(pool))
+	)
+
+	for i, endpoint := range pool {
+		health[i] = EndpointHealth{
+			DownUntil: endpoint.DownUntil,
+			URL:       endpoint.URL.String(),
+			Failures:  endpoint.Failures,
+			Healthy:   endpoint.DownUntil.IsZero(),
+		}
+	}
+
+	return health
+}
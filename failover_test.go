@@ -0,0 +1,272 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestClient_Endpoint(t *testing.T) {
+	t.Parallel()
+
+	var gotPath atomic.Value
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath.Store(r.URL.Path)
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint: ts.URL + "/proxy",
+		Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, _, err = client.User.Me(context.Background()); err != nil {
+		t.Fatalf("User.Me() error = %v", err)
+	}
+
+	if got := gotPath.Load(); got != "/proxy/user/me" {
+		t.Fatalf("request path = %v, want /proxy/user/me", got)
+	}
+}
+
+func TestClient_EndpointFailover(t *testing.T) {
+	t.Parallel()
+
+	var (
+		validTestData = xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "get-valid.json"))
+		primaryDown   atomic.Bool
+		primaryCalls  atomic.Int32
+		fallbackCalls atomic.Int32
+	)
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		primaryCalls.Add(1)
+
+		if primaryDown.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(validTestData)
+	}))
+	defer primary.Close()
+
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackCalls.Add(1)
+
+		if r.URL.Path != "/api/blueprint/blueprint-id" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(validTestData)
+	}))
+	defer fallback.Close()
+
+	const cooldown = 200 * time.Millisecond
+
+	cfg := &cloudcraft.Config{
+		Endpoint:          primary.URL,
+		FallbackEndpoints: []string{fallback.URL + "/api/"},
+		EndpointCooldown:  cooldown,
+		Key:               "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		// Failing over must not wait for the backoff.
+		RetryPolicy: &cloudcraft.RetryPolicy{MinRetryDelay: time.Hour, MaxRetryDelay: time.Hour},
+	}
+
+	client, err := cloudcraft.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+
+	primaryDown.Store(true)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err = client.Blueprint.Get(ctx, "blueprint-id"); err != nil {
+			t.Fatalf("Blueprint.Get() error = %v", err)
+		}
+	}
+
+	// The second call went straight to the fallback endpoint.
+	if primaryCalls.Load() != 1 || fallbackCalls.Load() != 2 {
+		t.Fatalf("calls = %d to the primary, %d to the fallback, want 1 and 2", primaryCalls.Load(), fallbackCalls.Load())
+	}
+
+	health := client.EndpointHealth()
+	if len(health) != 2 {
+		t.Fatalf("EndpointHealth() = %+v, want 2 endpoints", health)
+	}
+
+	if health[0].Healthy || health[0].Failures != 1 || health[0].DownUntil.IsZero() {
+		t.Fatalf("EndpointHealth()[0] = %+v, want an unhealthy endpoint", health[0])
+	}
+
+	if !health[1].Healthy || health[1].URL != fallback.URL+"/api/" {
+		t.Fatalf("EndpointHealth()[1] = %+v, want a healthy endpoint", health[1])
+	}
+
+	// Once the cool-down is over, the primary endpoint is preferred again.
+	primaryDown.Store(false)
+	time.Sleep(cooldown)
+
+	if _, _, err = client.Blueprint.Get(ctx, "blueprint-id"); err != nil {
+		t.Fatalf("Blueprint.Get() error = %v", err)
+	}
+
+	if primaryCalls.Load() != 2 || !client.EndpointHealth()[0].Healthy {
+		t.Fatalf("calls to the primary = %d, health = %+v", primaryCalls.Load(), client.EndpointHealth())
+	}
+}
+
+func TestClient_EndpointFailover_ConnectionError(t *testing.T) {
+	t.Parallel()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer fallback.Close()
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint:          down.URL,
+		FallbackEndpoints: []string{fallback.URL},
+		Key:               "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, _, err = client.User.Me(context.Background()); err != nil {
+		t.Fatalf("User.Me() error = %v", err)
+	}
+
+	if health := client.EndpointHealth(); health[0].Healthy || !health[1].Healthy {
+		t.Fatalf("EndpointHealth() = %+v", health)
+	}
+}
+
+func TestClient_EndpointFailover_AllDown(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	primary := httptest.NewServer(handler)
+	defer primary.Close()
+
+	fallback := httptest.NewServer(handler)
+	defer fallback.Close()
+
+	const delay = 20 * time.Millisecond
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint:          primary.URL,
+		FallbackEndpoints: []string{fallback.URL},
+		Key:               "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		MaxRetries:        4,
+		RetryPolicy: &cloudcraft.RetryPolicy{
+			Jitter:        cloudcraft.NoJitter,
+			MinRetryDelay: delay,
+			MaxRetryDelay: delay,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	start := time.Now()
+
+	if _, _, err = client.User.Me(context.Background()); !errors.Is(err, cloudcraft.ErrMaxRetriesExceeded) {
+		t.Fatalf("User.Me() error = %v, want %v", err, cloudcraft.ErrMaxRetriesExceeded)
+	}
+
+	if got := calls.Load(); got != 5 {
+		t.Fatalf("calls = %d, want 5", got)
+	}
+
+	// Only the first retry fails over right away; once every endpoint is
+	// down, the retries wait for the backoff.
+	if elapsed := time.Since(start); elapsed < 3*delay {
+		t.Fatalf("User.Me() took %v, want at least %v", elapsed, 3*delay)
+	}
+}
+
+func TestConfig_Validate_Endpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		give cloudcraft.Config
+		want error
+	}{
+		{
+			name: "Endpoint without other fields",
+			give: cloudcraft.Config{
+				Endpoint: "https://cloudcraft.example.com/api",
+				Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+			},
+			want: nil,
+		},
+		{
+			name: "Endpoint with an invalid scheme",
+			give: cloudcraft.Config{
+				Endpoint: "ftp://cloudcraft.example.com",
+				Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+			},
+			want: cloudcraft.ErrInvalidEndpoint,
+		},
+		{
+			name: "Invalid fallback endpoint",
+			give: cloudcraft.Config{
+				Endpoint:          "https://cloudcraft.example.com",
+				FallbackEndpoints: []string{"api.cloudcraft.co"},
+				Key:               "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+			},
+			want: cloudcraft.ErrInvalidEndpoint,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.give.Validate(); !errors.Is(err, tt.want) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
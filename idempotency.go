@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+	"github.com/DataDog/cloudcraft-go/internal/xhttp"
+)
+
+// IdempotencyKeyHeader is the header used to send idempotency keys to the
+// Cloudcraft API.
+const IdempotencyKeyHeader string = "Idempotency-Key"
+
+// errDuplicateFound is returned by Client.do when the duplicate check of a
+// create request finds the resource it was about to create again.
+const errDuplicateFound xerrors.Error = "resource already created by a previous attempt"
+
+// errUnknownCreator is returned by Client.creatorID when the Cloudcraft API
+// does not identify the user the client is authenticated as.
+const errUnknownCreator xerrors.Error = "unknown creator"
+
+// duplicateCheckSkew is the tolerance applied to creation times when looking
+// for a resource created by a previous attempt, to account for clock skew
+// between the client and the Cloudcraft API.
+const duplicateCheckSkew time.Duration = time.Minute
+
+// duplicateCheckContextKey is the context key for duplicate checks.
+type duplicateCheckContextKey struct{}
+
+// duplicateCheck reports whether a resource that a non-idempotent request
+// attempted to create already exists.
+type duplicateCheck func(ctx context.Context) (bool, error)
+
+// ContextWithIdempotencyKey returns a copy of ctx that carries the given
+// idempotency key.
+//
+// Requests that modify resources, such as creating a blueprint or registering
+// an account, are only retried after an ambiguous failure if they carry an
+// idempotency key. The key is sent to the API in the Idempotency-Key header of
+// every attempt. Use a new key for every logical operation, for example one
+// returned by NewIdempotencyKey.
+//
+// It is a shorthand for ContextWithRequestOptions with WithIdempotencyKey.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return ContextWithRequestOptions(ctx, WithIdempotencyKey(key))
+}
+
+// NewIdempotencyKey returns a new random idempotency key.
+func NewIdempotencyKey() string {
+	var b [16]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand never fails on supported platforms, so fall back to the
+		// current time rather than surfacing an error.
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+
+	return hex.EncodeToString(b[:])
+}
+
+// idempotencyKeyFrom returns the idempotency key carried by ctx, if any.
+func idempotencyKeyFrom(ctx context.Context) string {
+	return requestOptionsFrom(ctx).idempotencyKey
+}
+
+// withDuplicateCheck returns a copy of ctx that carries the given duplicate
+// check.
+func withDuplicateCheck(ctx context.Context, check duplicateCheck) context.Context {
+	return context.WithValue(ctx, duplicateCheckContextKey{}, check)
+}
+
+// duplicateCheckFrom returns the duplicate check carried by ctx, if any.
+func duplicateCheckFrom(ctx context.Context) duplicateCheck {
+	check, _ := ctx.Value(duplicateCheckContextKey{}).(duplicateCheck)
+
+	return check
+}
+
+// isIdempotent reports whether requests with the given method can be sent
+// more than once without changing the outcome.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// canRetry reports whether a failed attempt to send req can be safely retried
+// given the response and error it produced.
+//
+// Idempotent requests and requests carrying an idempotency key can always be
+// retried. Other requests are retried only when the API certainly did not
+// process them, or when their duplicate check confirms that the resource they
+// create does not exist yet. In that case, canRetry returns the check, which
+// must be run right before the next attempt, once the backoff is over, so that
+// it sees a resource whose creation was still in progress.
+func canRetry(req *http.Request, resp *http.Response, err error) (bool, duplicateCheck) {
+	if isIdempotent(req.Method) || req.Header.Get(IdempotencyKeyHeader) != "" {
+		return true, nil
+	}
+
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true, nil
+	}
+
+	if err != nil && xhttp.IsUnsentError(err) {
+		return true, nil
+	}
+
+	check := duplicateCheckFrom(req.Context())
+
+	return check != nil, check
+}
+
+// creatorID returns the ID of the user the client is authenticated as, which
+// the Cloudcraft API records as the creator of the resources the client
+// creates. Duplicate checks only match resources created by this user, so that
+// a resource with the same name created by someone else is never mistaken for
+// the one a previous attempt created.
+func (c *Client) creatorID(ctx context.Context) (string, error) {
+	user, _, err := c.User.Me(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	if user == nil || user.ID == "" {
+		return "", errUnknownCreator
+	}
+
+	return user.ID, nil
+}
+
+// createdSince reports whether a resource created at the given time may have
+// been created by a request started at start.
+func createdSince(createdAt, start time.Time) bool {
+	return !createdAt.Before(start.Add(-duplicateCheckSkew))
+}
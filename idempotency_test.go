@@ -0,0 +1,219 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+// fastRetries configures a client to retry requests without noticeable delay.
+func fastRetries(cfg *cloudcraft.Config) {
+	cfg.RetryPolicy = &cloudcraft.RetryPolicy{
+		MinRetryDelay: time.Millisecond,
+		MaxRetryDelay: time.Millisecond,
+	}
+}
+
+func TestNewIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	var (
+		first  = cloudcraft.NewIdempotencyKey()
+		second = cloudcraft.NewIdempotencyKey()
+	)
+
+	if first == "" || first == second {
+		t.Fatalf("NewIdempotencyKey() = %q and %q, want two distinct non-empty keys", first, second)
+	}
+}
+
+// _testCreatorID is the ID of the user the mock client is authenticated as.
+const _testCreatorID string = "9a3e5b1c-2d4f-4e6a-8b7c-1d2e3f4a5b6c"
+
+func TestBlueprintService_Create_Retries(t *testing.T) {
+	t.Parallel()
+
+	createTestData := xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "create-valid.json"))
+
+	tests := []struct {
+		name           string
+		giveStatuses   []int
+		giveKey        string
+		giveListed     bool
+		giveForeign    bool
+		giveListFails  bool
+		wantPosts      int
+		wantKeyHeader  bool
+		wantErr        bool
+		wantExistingID string
+	}{
+		{
+			name:          "Ambiguous failure with failing duplicate check is not retried",
+			giveStatuses:  []int{http.StatusBadGateway, http.StatusOK},
+			giveListFails: true,
+			wantPosts:     1,
+			wantErr:       true,
+		},
+		{
+			name:         "Ambiguous failure without existing blueprint is retried",
+			giveStatuses: []int{http.StatusBadGateway, http.StatusOK},
+			wantPosts:    2,
+			wantErr:      false,
+		},
+		{
+			name:           "Ambiguous failure with existing blueprint is not retried",
+			giveStatuses:   []int{http.StatusGatewayTimeout, http.StatusOK},
+			giveListed:     true,
+			wantPosts:      1,
+			wantErr:        false,
+			wantExistingID: "0d1c6a5e-7f0b-4f6e-9a49-0a6f5d5e4b21",
+		},
+		{
+			name:         "Ambiguous failure with foreign blueprint of the same name is retried",
+			giveStatuses: []int{http.StatusGatewayTimeout, http.StatusOK},
+			giveListed:   true,
+			giveForeign:  true,
+			wantPosts:    2,
+			wantErr:      false,
+		},
+		{
+			name:          "Ambiguous failure with idempotency key is retried",
+			giveStatuses:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			giveKey:       "7d9f2c1e4b6a8d0f",
+			giveListed:    true,
+			wantPosts:     2,
+			wantKeyHeader: true,
+			wantErr:       false,
+		},
+		{
+			name:          "Rate limited request is retried",
+			giveStatuses:  []int{http.StatusTooManyRequests, http.StatusOK},
+			giveListFails: true,
+			wantPosts:     2,
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu    sync.Mutex
+				posts int
+				keys  []string
+			)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/user/me", func(w http.ResponseWriter, _ *http.Request) {
+				json.NewEncoder(w).Encode(&cloudcraft.User{ID: _testCreatorID})
+			})
+			mux.HandleFunc("/blueprint", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && tt.giveListFails {
+					w.WriteHeader(http.StatusBadRequest)
+
+					return
+				}
+
+				if r.Method == http.MethodGet {
+					var blueprints []*cloudcraft.Blueprint
+
+					if tt.giveListed {
+						creatorID := _testCreatorID
+						if tt.giveForeign {
+							creatorID = "f3b0c3d2-9c1e-4a57-8d6b-2e4f1a7c9b05"
+						}
+
+						blueprints = append(blueprints, &cloudcraft.Blueprint{
+							ID:        "0d1c6a5e-7f0b-4f6e-9a49-0a6f5d5e4b21",
+							Name:      "My new blueprint",
+							CreatorID: creatorID,
+							CreatedAt: time.Now(),
+						})
+					}
+
+					json.NewEncoder(w).Encode(map[string]any{"blueprints": blueprints})
+
+					return
+				}
+
+				mu.Lock()
+				status := tt.giveStatuses[min(posts, len(tt.giveStatuses)-1)]
+				posts++
+				keys = append(keys, r.Header.Get(cloudcraft.IdempotencyKeyHeader))
+				mu.Unlock()
+
+				w.WriteHeader(status)
+
+				if status == http.StatusOK {
+					w.Write(createTestData)
+				}
+			})
+
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			endpoint, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			metrics := cloudcraft.NewInMemoryMetrics()
+
+			client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+				fastRetries(cfg)
+
+				cfg.Metrics = metrics
+			})
+
+			ctx := context.Background()
+			if tt.giveKey != "" {
+				ctx = cloudcraft.ContextWithIdempotencyKey(ctx, tt.giveKey)
+			}
+
+			got, _, err := client.Blueprint.Create(ctx, &cloudcraft.Blueprint{
+				Data: &cloudcraft.BlueprintData{Name: "My new blueprint"},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BlueprintService.Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if posts != tt.wantPosts {
+				t.Errorf("POST requests = %d, want %d", posts, tt.wantPosts)
+			}
+
+			for _, key := range keys {
+				if (key != "") != tt.wantKeyHeader || (tt.wantKeyHeader && key != tt.giveKey) {
+					t.Errorf("%s header = %q, want %q", cloudcraft.IdempotencyKeyHeader, key, tt.giveKey)
+				}
+			}
+
+			if tt.wantExistingID != "" && (got == nil || got.ID != tt.wantExistingID) {
+				t.Errorf("BlueprintService.Create() = %+v, want blueprint %q", got, tt.wantExistingID)
+			}
+
+			// A call that finds its duplicate is reported as successful.
+			if op, _ := metrics.Operation("Blueprint", "Create"); op.Errors != 0 && !tt.wantErr {
+				t.Errorf("Blueprint.Create failed calls = %d, want 0", op.Errors)
+			}
+		})
+	}
+}
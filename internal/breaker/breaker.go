@@ -0,0 +1,245 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+// Package breaker implements a circuit breaker that stops sending requests to
+// a failing service for a while.
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	// Closed is the state of a Breaker that lets requests through and watches
+	// their failure rate.
+	Closed State = iota
+
+	// Open is the state of a Breaker that rejects requests until its cool-down
+	// period is over.
+	Open
+
+	// HalfOpen is the state of a Breaker that lets a few probe requests
+	// through to tell whether the service recovered.
+	HalfOpen
+)
+
+// Config holds the settings of a Breaker.
+type Config struct {
+	// OnStateChange is called after every change of state, outside of the
+	// lock of the Breaker. It is optional.
+	OnStateChange func(from, to State)
+
+	// Now returns the current time. It is optional, and time.Now is used if
+	// it is nil.
+	Now func() time.Time
+
+	// FailureRate is the rate of failures, between 0 and 1, over the window of
+	// recent requests at which the Breaker opens.
+	FailureRate float64
+
+	// Window is the number of recent requests over which the failure rate is
+	// computed.
+	Window int
+
+	// MinRequests is the minimum number of requests in the window before the
+	// Breaker can open.
+	MinRequests int
+
+	// Cooldown is how long the Breaker stays open before letting probes
+	// through.
+	Cooldown time.Duration
+
+	// Probes is the number of requests let through, and that must succeed, in
+	// the half-open state before the Breaker closes.
+	Probes int
+}
+
+// Breaker is a circuit breaker. Requests ask for permission with Allow, then
+// report their outcome with Record, or with Release if it must be ignored.
+//
+// A Breaker is safe for concurrent use.
+type Breaker struct {
+	cfg Config
+
+	// openedAt is when the Breaker last opened.
+	openedAt time.Time
+
+	// outcomes is a ring buffer of the outcomes of the recent requests, true
+	// for failures.
+	outcomes []bool
+
+	// generation changes with every change of state, so that outcomes of
+	// requests allowed in an earlier state are ignored.
+	generation uint64
+
+	state    State
+	next     int
+	count    int
+	failures int
+
+	// probes is the number of probes let through in the half-open state, and
+	// successes the number of them that succeeded.
+	probes    int
+	successes int
+
+	mu sync.Mutex
+}
+
+// New returns a new closed Breaker with the given settings.
+func New(cfg Config) *Breaker {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	cfg.Window = max(cfg.Window, 1)
+	cfg.Probes = max(cfg.Probes, 1)
+
+	return &Breaker{
+		cfg:      cfg,
+		outcomes: make([]bool, cfg.Window),
+	}
+}
+
+// State returns the current state of the Breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// An open Breaker whose cool-down period is over is ready for probes.
+	if b.state == Open && !b.cfg.Now().Before(b.openedAt.Add(b.cfg.Cooldown)) {
+		return HalfOpen
+	}
+
+	return b.state
+}
+
+// Allow reports whether a request can be sent. If it can, it returns the
+// generation to pass to Record or Release once the request is done.
+func (b *Breaker) Allow() (uint64, bool) {
+	b.mu.Lock()
+
+	from := b.state
+
+	if b.state == Open {
+		if b.cfg.Now().Before(b.openedAt.Add(b.cfg.Cooldown)) {
+			b.mu.Unlock()
+
+			return 0, false
+		}
+
+		b.setState(HalfOpen)
+	}
+
+	allowed := true
+
+	if b.state == HalfOpen {
+		allowed = b.probes < b.cfg.Probes
+		if allowed {
+			b.probes++
+		}
+	}
+
+	generation, to := b.generation, b.state
+
+	b.mu.Unlock()
+
+	b.notify(from, to)
+
+	return generation, allowed
+}
+
+// Record reports the outcome of a request allowed in the given generation.
+func (b *Breaker) Record(generation uint64, success bool) {
+	b.mu.Lock()
+
+	from := b.state
+
+	if generation != b.generation {
+		b.mu.Unlock()
+
+		return
+	}
+
+	switch b.state {
+	case Closed:
+		b.observe(!success)
+
+		if b.count >= b.cfg.MinRequests && float64(b.failures) >= b.cfg.FailureRate*float64(b.count) {
+			b.setState(Open)
+		}
+	case HalfOpen:
+		if !success {
+			b.setState(Open)
+
+			break
+		}
+
+		b.successes++
+
+		if b.successes >= b.cfg.Probes {
+			b.setState(Closed)
+		}
+	case Open:
+	}
+
+	to := b.state
+
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// Release reports that a request allowed in the given generation ended
+// without an outcome, such as a canceled request, which frees its probe slot
+// in the half-open state.
+func (b *Breaker) Release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation && b.state == HalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// observe adds the outcome of a request to the window.
+func (b *Breaker) observe(failure bool) {
+	if b.count == len(b.outcomes) {
+		if b.outcomes[b.next] {
+			b.failures--
+		}
+	} else {
+		b.count++
+	}
+
+	b.outcomes[b.next] = failure
+	b.next = (b.next + 1) % len(b.outcomes)
+
+	if failure {
+		b.failures++
+	}
+}
+
+// setState moves the Breaker to the given state, resetting the counters of
+// the previous one. The caller must hold the lock.
+func (b *Breaker) setState(state State) {
+	b.state = state
+	b.generation++
+	b.next, b.count, b.failures = 0, 0, 0
+	b.probes, b.successes = 0, 0
+
+	if state == Open {
+		b.openedAt = b.cfg.Now()
+	}
+}
+
+// notify calls the OnStateChange callback if the state changed.
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, to)
+	}
+}
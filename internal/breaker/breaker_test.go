@@ -0,0 +1,170 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package breaker_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/breaker"
+)
+
+// clock is a fake clock for breakers.
+type clock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clk         = &clock{now: time.Unix(0, 0)}
+		transitions [][2]breaker.State
+	)
+
+	b := breaker.New(breaker.Config{
+		OnStateChange: func(from, to breaker.State) {
+			transitions = append(transitions, [2]breaker.State{from, to})
+		},
+		Now:         clk.Now,
+		FailureRate: 0.5,
+		Window:      4,
+		MinRequests: 4,
+		Cooldown:    time.Minute,
+		Probes:      2,
+	})
+
+	record := func(success bool) {
+		t.Helper()
+
+		generation, ok := b.Allow()
+		if !ok {
+			t.Fatalf("Allow() = false in state %v, want true", b.State())
+		}
+
+		b.Record(generation, success)
+	}
+
+	// A single failure among the minimum number of requests keeps it closed.
+	for _, success := range []bool{true, true, false, true} {
+		record(success)
+	}
+
+	if got := b.State(); got != breaker.Closed {
+		t.Fatalf("State() = %v, want %v", got, breaker.Closed)
+	}
+
+	// The oldest outcomes leave the window, which tips the failure rate.
+	record(false)
+
+	if got := b.State(); got != breaker.Open {
+		t.Fatalf("State() = %v, want %v", got, breaker.Open)
+	}
+
+	if _, ok := b.Allow(); ok {
+		t.Fatal("Allow() = true while open, want false")
+	}
+
+	// After the cool-down, a limited number of probes are let through.
+	clk.Advance(time.Minute)
+
+	if got := b.State(); got != breaker.HalfOpen {
+		t.Fatalf("State() = %v, want %v", got, breaker.HalfOpen)
+	}
+
+	first, ok := b.Allow()
+	if !ok {
+		t.Fatal("Allow() = false for the first probe, want true")
+	}
+
+	second, ok := b.Allow()
+	if !ok {
+		t.Fatal("Allow() = false for the second probe, want true")
+	}
+
+	if _, ok = b.Allow(); ok {
+		t.Fatal("Allow() = true beyond the probes, want false")
+	}
+
+	// A canceled probe frees its slot.
+	b.Release(second)
+
+	if second, ok = b.Allow(); !ok {
+		t.Fatal("Allow() = false after a release, want true")
+	}
+
+	b.Record(first, true)
+	b.Record(second, true)
+
+	if got := b.State(); got != breaker.Closed {
+		t.Fatalf("State() = %v, want %v", got, breaker.Closed)
+	}
+
+	want := [][2]breaker.State{
+		{breaker.Closed, breaker.Open},
+		{breaker.Open, breaker.HalfOpen},
+		{breaker.HalfOpen, breaker.Closed},
+	}
+
+	if !reflect.DeepEqual(transitions, want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestBreaker_FailedProbe(t *testing.T) {
+	t.Parallel()
+
+	clk := &clock{now: time.Unix(0, 0)}
+
+	b := breaker.New(breaker.Config{
+		Now:         clk.Now,
+		FailureRate: 1,
+		Window:      1,
+		MinRequests: 1,
+		Cooldown:    time.Second,
+		Probes:      1,
+	})
+
+	generation, _ := b.Allow()
+	b.Record(generation, false)
+
+	clk.Advance(time.Second)
+
+	probe, ok := b.Allow()
+	if !ok {
+		t.Fatal("Allow() = false after the cool-down, want true")
+	}
+
+	b.Record(probe, false)
+
+	if got := b.State(); got != breaker.Open {
+		t.Fatalf("State() = %v, want %v", got, breaker.Open)
+	}
+
+	// Outcomes of requests allowed before the last change of state are
+	// ignored.
+	b.Record(generation, true)
+
+	if _, ok = b.Allow(); ok {
+		t.Fatal("Allow() = true after a failed probe, want false")
+	}
+}
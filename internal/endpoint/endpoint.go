@@ -61,3 +61,45 @@ func Parse(scheme, host, port, path string) (*url.URL, error) {
 
 	return uri, nil
 }
+
+// ParseURL parses a full URL, such as "https://api.cloudcraft.co/", into an
+// *url.URL. The path of the returned URL always ends with a slash, so that API
+// paths can be appended to it.
+func ParseURL(raw string) (*url.URL, error) {
+	uri, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	if uri.Scheme == "" || uri.Host == "" {
+		return nil, ErrMissingFragment
+	}
+
+	if uri.Scheme != "https" && uri.Scheme != "http" {
+		return nil, fmt.Errorf("%w", ErrInvalidScheme)
+	}
+
+	if !strings.HasSuffix(uri.Path, _Slash) {
+		uri.Path += _Slash
+	}
+
+	uri.RawPath = ""
+
+	return uri, nil
+}
+
+// Rebase returns a copy of u, a URL under the base URL from, moved under the
+// base URL to.
+func Rebase(u, from, to *url.URL) *url.URL {
+	rebased := *u
+
+	rebased.Scheme = to.Scheme
+	rebased.Host = to.Host
+	rebased.RawPath = ""
+
+	if rest, ok := strings.CutPrefix(u.Path, from.Path); ok {
+		rebased.Path = strings.TrimSuffix(to.Path, _Slash) + _Slash + strings.TrimPrefix(rest, _Slash)
+	}
+
+	return &rebased
+}
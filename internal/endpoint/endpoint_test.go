@@ -88,3 +88,56 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		give      string
+		want      string
+		wantError bool
+	}{
+		{name: "Host only", give: "https://api.cloudcraft.co", want: "https://api.cloudcraft.co/"},
+		{name: "Port and path", give: "http://localhost:8080/api", want: "http://localhost:8080/api/"},
+		{name: "Missing scheme", give: "api.cloudcraft.co", wantError: true},
+		{name: "Invalid scheme", give: "ftp://api.cloudcraft.co", wantError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := endpoint.ParseURL(tt.give)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseURL() error = %v, wantError %v", err, tt.wantError)
+			}
+
+			if err == nil && got.String() != tt.want {
+				t.Fatalf("ParseURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRebase(t *testing.T) {
+	t.Parallel()
+
+	var (
+		from, _ = url.Parse("https://proxy.example.com/cloudcraft/")
+		to, _   = url.Parse("https://api.cloudcraft.co/")
+		give, _ = url.Parse("https://proxy.example.com/cloudcraft/blueprint/id?format=png")
+	)
+
+	got := endpoint.Rebase(give, from, to)
+
+	if want := "https://api.cloudcraft.co/blueprint/id?format=png"; got.String() != want {
+		t.Fatalf("Rebase() = %v, want %v", got, want)
+	}
+
+	if give.Host != "proxy.example.com" {
+		t.Fatal("Rebase() modified its input")
+	}
+}
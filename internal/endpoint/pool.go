@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package endpoint
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Pool is an ordered list of endpoints that tracks their health. Endpoints
+// that fail are put in a cool-down period, during which the next healthy
+// endpoint in the list is preferred.
+//
+// A Pool is safe for concurrent use.
+type Pool struct {
+	endpoints []*state
+	cooldown  time.Duration
+	mu        sync.Mutex
+}
+
+// state holds the health of an endpoint.
+type state struct {
+	url       *url.URL
+	downUntil time.Time
+	failures  int
+}
+
+// Health describes the health of an endpoint of a Pool.
+type Health struct {
+	// URL is the base URL of the endpoint.
+	URL *url.URL
+
+	// DownUntil is the end of the cool-down period of the endpoint. It is
+	// zero if the endpoint is healthy.
+	DownUntil time.Time
+
+	// Failures is the number of consecutive failures of the endpoint.
+	Failures int
+}
+
+// NewPool returns a new Pool of the given endpoints, in order of preference,
+// that puts failing endpoints in a cool-down period of the given duration.
+func NewPool(endpoints []*url.URL, cooldown time.Duration) *Pool {
+	pool := &Pool{
+		endpoints: make([]*state, len(endpoints)),
+		cooldown:  cooldown,
+	}
+
+	for i, endpoint := range endpoints {
+		pool.endpoints[i] = &state{url: endpoint}
+	}
+
+	return pool
+}
+
+// Pick returns the index and URL of the first healthy endpoint. If all of them
+// are cooling down, the one whose cool-down period ends first is returned.
+func (p *Pool) Pick() (int, *url.URL) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		now  = time.Now()
+		pick = 0
+	)
+
+	for i, endpoint := range p.endpoints {
+		if !endpoint.downUntil.After(now) {
+			return i, endpoint.url
+		}
+
+		if endpoint.downUntil.Before(p.endpoints[pick].downUntil) {
+			pick = i
+		}
+	}
+
+	return pick, p.endpoints[pick].url
+}
+
+// Healthy reports whether the endpoint at index i is healthy, that is not in
+// a cool-down period.
+func (p *Pool) Healthy(i int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return !p.endpoints[i].downUntil.After(time.Now())
+}
+
+// MarkFailure records a failure of the endpoint at index i, which starts its
+// cool-down period.
+func (p *Pool) MarkFailure(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.endpoints[i].failures++
+	p.endpoints[i].downUntil = time.Now().Add(p.cooldown)
+}
+
+// MarkSuccess records a success of the endpoint at index i, which makes it
+// healthy again.
+func (p *Pool) MarkSuccess(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.endpoints[i].failures = 0
+	p.endpoints[i].downUntil = time.Time{}
+}
+
+// Health returns the health of the endpoints, in order of preference.
+func (p *Pool) Health() []Health {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		now    = time.Now()
+		health = make([]Health, len(p.endpoints))
+	)
+
+	for i, endpoint := range p.endpoints {
+		health[i] = Health{
+			URL:      endpoint.url,
+			Failures: endpoint.failures,
+		}
+
+		if endpoint.downUntil.After(now) {
+			health[i].DownUntil = endpoint.downUntil
+		}
+	}
+
+	return health
+}
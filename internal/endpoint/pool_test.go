@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package endpoint_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/endpoint"
+)
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	var (
+		primary, _  = url.Parse("https://proxy.example.com/")
+		fallback, _ = url.Parse("https://api.cloudcraft.co/")
+		pool        = endpoint.NewPool([]*url.URL{primary, fallback}, time.Hour)
+	)
+
+	if i, _ := pool.Pick(); i != 0 {
+		t.Fatalf("Pick() = %d, want the primary endpoint", i)
+	}
+
+	pool.MarkFailure(0)
+
+	if i, _ := pool.Pick(); i != 1 {
+		t.Fatalf("Pick() = %d, want the fallback endpoint", i)
+	}
+
+	if pool.Healthy(0) || !pool.Healthy(1) {
+		t.Fatalf("Healthy() = %t, %t, want false, true", pool.Healthy(0), pool.Healthy(1))
+	}
+
+	// When every endpoint is down, the one that recovers first is picked.
+	pool.MarkFailure(1)
+
+	if i, _ := pool.Pick(); i != 0 || pool.Healthy(i) {
+		t.Fatalf("Pick() = %d, want the endpoint that failed first, still unhealthy", i)
+	}
+
+	pool.MarkSuccess(1)
+
+	health := pool.Health()
+	if health[0].Failures != 1 || health[0].DownUntil.IsZero() {
+		t.Fatalf("Health()[0] = %+v, want a failing endpoint", health[0])
+	}
+
+	if health[1].Failures != 0 || !health[1].DownUntil.IsZero() {
+		t.Fatalf("Health()[1] = %+v, want a healthy endpoint", health[1])
+	}
+}
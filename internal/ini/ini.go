@@ -0,0 +1,131 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+// Package ini provides parsers for INI-style configuration files and for
+// dotenv files.
+package ini
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+)
+
+const (
+	// ErrSyntax is returned when a file cannot be parsed.
+	ErrSyntax xerrors.Error = "syntax error"
+
+	errMissingEquals xerrors.Error = "missing '=' after key"
+	errMissingKey    xerrors.Error = "missing key before '='"
+)
+
+// Parse parses an INI file made of named sections of "key = value" pairs:
+//
+//	# A comment.
+//	[section]
+//	key = value
+//
+// It returns the pairs of each section, keyed by section name. Pairs that
+// appear before the first section belong to the section named "". Lines
+// starting with '#' or ';' are comments, and values may be quoted.
+func Parse(r io.Reader) (map[string]map[string]string, error) {
+	var (
+		sections = map[string]map[string]string{}
+		section  = ""
+		scanner  = bufio.NewScanner(r)
+	)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case text == "" || text[0] == '#' || text[0] == ';':
+			continue
+		case text[0] == '[':
+			if text[len(text)-1] != ']' {
+				return nil, fmt.Errorf("%w: line %d: unterminated section name", ErrSyntax, line)
+			}
+
+			section = strings.TrimSpace(text[1 : len(text)-1])
+
+			if _, ok := sections[section]; !ok {
+				sections[section] = map[string]string{}
+			}
+		default:
+			key, value, err := parsePair(text)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", ErrSyntax, line, err)
+			}
+
+			if _, ok := sections[section]; !ok {
+				sections[section] = map[string]string{}
+			}
+
+			sections[section][key] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return sections, nil
+}
+
+// ParseEnv parses a dotenv file made of "KEY=value" pairs, one per line, as
+// also understood by make. Lines starting with '#' are comments, an optional
+// "export " prefix is ignored, and values may be quoted.
+func ParseEnv(r io.Reader) (map[string]string, error) {
+	var (
+		vars    = map[string]string{}
+		scanner = bufio.NewScanner(r)
+	)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || text[0] == '#' {
+			continue
+		}
+
+		key, value, err := parsePair(strings.TrimPrefix(text, "export "))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrSyntax, line, err)
+		}
+
+		vars[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return vars, nil
+}
+
+// parsePair parses a "key = value" pair, removing the quotes around the value
+// if any.
+func parsePair(text string) (string, string, error) {
+	key, value, ok := strings.Cut(text, "=")
+	if !ok {
+		// The line is not quoted in the error, as it may hold a secret.
+		return "", "", errMissingEquals
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", "", errMissingKey
+	}
+
+	value = strings.TrimSpace(value)
+
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+
+	return key, value, nil
+}
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package ini_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go/internal/ini"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		give    string
+		want    map[string]map[string]string
+		wantErr error
+	}{
+		{
+			name: "Sections with comments and quotes",
+			give: `
+# Global settings.
+timeout = 1m
+
+[default]
+host = api.cloudcraft.co
+
+; Self-hosted instance.
+[ self-hosted ]
+host = "cloudcraft.example.com"
+key = 'a=b'
+`,
+			want: map[string]map[string]string{
+				"":            {"timeout": "1m"},
+				"default":     {"host": "api.cloudcraft.co"},
+				"self-hosted": {"host": "cloudcraft.example.com", "key": "a=b"},
+			},
+		},
+		{
+			name:    "Unterminated section",
+			give:    "[default\nhost = example.com",
+			wantErr: ini.ErrSyntax,
+		},
+		{
+			name:    "Missing equal sign",
+			give:    "[default]\nhost example.com",
+			wantErr: ini.ErrSyntax,
+		},
+		{
+			name:    "Missing key",
+			give:    "[default]\n= example.com",
+			wantErr: ini.ErrSyntax,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ini.Parse(strings.NewReader(tt.give))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEnv(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		give    string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name: "Variables with comments, exports and quotes",
+			give: `
+# Credentials for the integration tests.
+CLOUDCRAFT_API_KEY=not-a-real-key
+export CLOUDCRAFT_HOST = "api.cloudcraft.co"
+CLOUDCRAFT_PATH='/'
+`,
+			want: map[string]string{
+				"CLOUDCRAFT_API_KEY": "not-a-real-key",
+				"CLOUDCRAFT_HOST":    "api.cloudcraft.co",
+				"CLOUDCRAFT_PATH":    "/",
+			},
+		},
+		{
+			name:    "Missing equal sign",
+			give:    "CLOUDCRAFT_API_KEY",
+			wantErr: ini.ErrSyntax,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ini.ParseEnv(strings.NewReader(tt.give))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseEnv() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package ratelimit
+
+import "sync"
+
+// BudgetStats holds statistics about the withdrawals from a Budget.
+type BudgetStats struct {
+	// Tokens is the number of tokens left in the budget.
+	Tokens float64
+
+	// Withdrawn is the number of calls to Withdraw that took a token.
+	Withdrawn int64
+
+	// Denied is the number of calls to Withdraw that found no token left.
+	Denied int64
+}
+
+// Budget is a token bucket refilled by events rather than by time, such as a
+// retry budget: every retry withdraws a token, and every success deposits a
+// fraction of one, so that retries are bounded by the rate of successes.
+type Budget struct {
+	// stats holds the withdrawal statistics of the budget.
+	stats BudgetStats
+
+	// max is the maximum number of tokens in the budget.
+	max float64
+
+	// ratio is the number of tokens added by a deposit.
+	ratio float64
+
+	mu sync.Mutex
+}
+
+// NewBudget returns a new Budget holding up to max tokens, to which every
+// deposit adds ratio tokens. The budget starts full.
+func NewBudget(max, ratio float64) *Budget {
+	return &Budget{
+		stats: BudgetStats{Tokens: max},
+		max:   max,
+		ratio: ratio,
+	}
+}
+
+// Withdraw takes a token from the budget and reports whether one was left.
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stats.Tokens < 1 {
+		b.stats.Denied++
+
+		return false
+	}
+
+	b.stats.Tokens--
+	b.stats.Withdrawn++
+
+	return true
+}
+
+// Deposit adds a fraction of a token to the budget, up to its maximum.
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.Tokens = min(b.stats.Tokens+b.ratio, b.max)
+}
+
+// Stats returns the withdrawal statistics of the budget.
+func (b *Budget) Stats() BudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.stats
+}
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package ratelimit_test
+
+import (
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go/internal/ratelimit"
+)
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	budget := ratelimit.NewBudget(2, 0.5)
+
+	// The budget starts full.
+	for i := 0; i < 2; i++ {
+		if !budget.Withdraw() {
+			t.Fatalf("Withdraw() #%d = false, want true", i+1)
+		}
+	}
+
+	if budget.Withdraw() {
+		t.Fatal("Withdraw() = true on an empty budget, want false")
+	}
+
+	// A single deposit is not enough for a whole token.
+	budget.Deposit()
+
+	if budget.Withdraw() {
+		t.Fatal("Withdraw() = true with half a token, want false")
+	}
+
+	budget.Deposit()
+
+	if !budget.Withdraw() {
+		t.Fatal("Withdraw() = false after two deposits, want true")
+	}
+
+	// Deposits never overfill the budget.
+	for i := 0; i < 10; i++ {
+		budget.Deposit()
+	}
+
+	want := ratelimit.BudgetStats{Tokens: 2, Withdrawn: 3, Denied: 2}
+
+	if got := budget.Stats(); got != want {
+		t.Fatalf("Stats() = %+v, want %+v", got, want)
+	}
+}
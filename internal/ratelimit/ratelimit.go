@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+// Package ratelimit provides a token bucket rate limiter safe for concurrent
+// use.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Stats holds statistics about the time spent waiting on a Limiter.
+type Stats struct {
+	// Requests is the number of calls to Wait.
+	Requests int64
+
+	// Delayed is the number of calls to Wait that had to wait for a token.
+	Delayed int64
+
+	// TotalWait is the total time spent waiting for tokens.
+	TotalWait time.Duration
+
+	// MaxWait is the longest time spent waiting for a single token.
+	MaxWait time.Duration
+}
+
+// Limiter is a token bucket rate limiter. Tokens are added to the bucket at a
+// fixed rate, up to a maximum burst size, and every call to Wait takes one.
+type Limiter struct {
+	// last is the last time tokens were added to the bucket.
+	last time.Time
+
+	// stats holds the wait statistics of the limiter.
+	stats Stats
+
+	// rate is the number of tokens added to the bucket per second.
+	rate float64
+
+	// burst is the maximum number of tokens in the bucket.
+	burst float64
+
+	// tokens is the number of tokens in the bucket. It becomes negative when
+	// callers reserve tokens that are not available yet.
+	tokens float64
+
+	mu sync.Mutex
+}
+
+// New returns a new Limiter that allows rate events per second with bursts of
+// up to burst events. The bucket starts full. If burst is lower than one, it
+// is set to one.
+func New(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		last:   time.Now(),
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Wait blocks until a token is available or the context is canceled, and
+// returns the time spent waiting. If the context is canceled first, the token
+// is given back and the context's error is returned.
+func (l *Limiter) Wait(ctx context.Context) (time.Duration, error) {
+	delay := l.reserve()
+
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.cancel()
+
+		return 0, fmt.Errorf("%w", ctx.Err())
+	case <-timer.C:
+		l.record(delay)
+
+		return delay, nil
+	}
+}
+
+// Stats returns the wait statistics of the limiter.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+// reserve takes a token from the bucket and returns how long the caller must
+// wait before using it.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	l.stats.Requests++
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a token reserved by a caller that stopped waiting.
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+// record adds a completed wait to the statistics of the limiter.
+func (l *Limiter) record(delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.Delayed++
+	l.stats.TotalWait += delay
+	l.stats.MaxWait = max(l.stats.MaxWait, delay)
+}
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/ratelimit"
+)
+
+func TestLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	var (
+		limiter = ratelimit.New(20, 2)
+		ctx     = context.Background()
+		start   = time.Now()
+	)
+
+	// The first two calls use the initial burst, the next two wait for new
+	// tokens at a rate of one every 50 milliseconds.
+	for i := 0; i < 4; i++ {
+		if _, err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Wait() took %v for 4 tokens, want at least 90ms", elapsed)
+	}
+
+	stats := limiter.Stats()
+
+	if stats.Requests != 4 {
+		t.Errorf("Stats().Requests = %d, want 4", stats.Requests)
+	}
+
+	if stats.Delayed != 2 {
+		t.Errorf("Stats().Delayed = %d, want 2", stats.Delayed)
+	}
+
+	if stats.TotalWait <= 0 || stats.MaxWait <= 0 || stats.MaxWait > stats.TotalWait {
+		t.Errorf("Stats() = %+v, want positive waits with MaxWait <= TotalWait", stats)
+	}
+}
+
+func TestLimiter_Wait_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	limiter := ratelimit.New(0.1, 1)
+
+	if _, err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if stats := limiter.Stats(); stats.Delayed != 0 {
+		t.Errorf("Stats().Delayed = %d, want 0", stats.Delayed)
+	}
+}
@@ -0,0 +1,198 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package xhttp
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EncodingGzip is the content coding of gzip-compressed bodies.
+const EncodingGzip string = "gzip"
+
+// Decoder returns a reader that decompresses the data read from r.
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+// DecodeGzip is the Decoder for gzip-compressed bodies.
+func DecodeGzip(r io.Reader) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return zr, nil
+}
+
+// ContentDecoder negotiates compressed responses through the Accept-Encoding
+// header and transparently decompresses them.
+//
+// Unlike the decompression built into http.Transport, it measures the body
+// both as received and after decompression; see DecodedBody.
+type ContentDecoder struct {
+	decoders map[string]Decoder
+	accept   string
+}
+
+// NewContentDecoder returns a ContentDecoder for the content codings in
+// decoders. Content codings are matched case-insensitively.
+func NewContentDecoder(decoders map[string]Decoder) *ContentDecoder {
+	codings := make(map[string]Decoder, len(decoders))
+	names := make([]string, 0, len(decoders))
+
+	for name, decoder := range decoders {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || decoder == nil {
+			continue
+		}
+
+		if _, ok := codings[name]; !ok {
+			names = append(names, name)
+		}
+
+		codings[name] = decoder
+	}
+
+	// Sort for a stable header, preferring the codings that are not gzip since
+	// they are only registered on purpose.
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == EncodingGzip) != (names[j] == EncodingGzip) {
+			return names[j] == EncodingGzip
+		}
+
+		return names[i] < names[j]
+	})
+
+	return &ContentDecoder{
+		decoders: codings,
+		accept:   strings.Join(names, ", "),
+	}
+}
+
+// AcceptEncoding returns the value of the Accept-Encoding header listing the
+// supported content codings.
+func (d *ContentDecoder) AcceptEncoding() string {
+	return d.accept
+}
+
+// Decode replaces the body of resp with a DecodedBody if it is compressed
+// with one of the supported content codings. Other responses are left
+// untouched.
+func (d *ContentDecoder) Decode(resp *http.Response) {
+	if resp.Uncompressed {
+		return
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+
+	decoder, ok := d.decoders[encoding]
+	if !ok {
+		return
+	}
+
+	resp.Body = &DecodedBody{
+		raw:      &MeteredReader{R: resp.Body},
+		closer:   resp.Body,
+		decoder:  decoder,
+		encoding: encoding,
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// DecodedBody is the body of a response decompressed by a ContentDecoder.
+type DecodedBody struct {
+	raw      *MeteredReader
+	closer   io.Closer
+	decoder  Decoder
+	decoded  io.ReadCloser
+	encoding string
+}
+
+// Read implements the io.Reader interface. The decoder is created on the first
+// read, so that empty bodies can be closed without an error.
+func (b *DecodedBody) Read(p []byte) (int, error) {
+	if b.decoded == nil {
+		decoded, err := b.decoder(b.raw)
+		if err != nil {
+			// An empty body has nothing to decode.
+			if b.raw.N == 0 && errors.Is(err, io.EOF) {
+				return 0, io.EOF
+			}
+
+			return 0, fmt.Errorf("%w", err)
+		}
+
+		b.decoded = decoded
+	}
+
+	n, err := b.decoded.Read(p)
+	if err != nil && err != io.EOF { //nolint:errorlint // io.EOF is returned as is by contract.
+		return n, fmt.Errorf("%w", err)
+	}
+
+	return n, err //nolint:wrapcheck // io.EOF must not be wrapped.
+}
+
+// Close implements the io.Closer interface.
+func (b *DecodedBody) Close() error {
+	if b.decoded != nil {
+		_ = b.decoded.Close()
+	}
+
+	if err := b.closer.Close(); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// Encoding returns the content coding the body was compressed with.
+func (b *DecodedBody) Encoding() string {
+	return b.encoding
+}
+
+// WireSize returns the number of compressed bytes read from the network so
+// far.
+func (b *DecodedBody) WireSize() int64 {
+	return b.raw.N
+}
+
+// ReadDuration returns the time spent reading compressed bytes from the
+// network so far.
+func (b *DecodedBody) ReadDuration() time.Duration {
+	return b.raw.Elapsed
+}
+
+// MeteredReader counts the bytes read from an io.Reader and the time spent
+// reading them.
+type MeteredReader struct {
+	// R is the underlying reader.
+	R io.Reader
+
+	// N is the number of bytes read so far.
+	N int64
+
+	// Elapsed is the time spent in calls to the Read method of R so far.
+	Elapsed time.Duration
+}
+
+// Read implements the io.Reader interface.
+func (m *MeteredReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := m.R.Read(p)
+	m.Elapsed += time.Since(start)
+	m.N += int64(n)
+
+	return n, err //nolint:wrapcheck // Errors, io.EOF included, are returned as is.
+}
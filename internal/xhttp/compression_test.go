@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package xhttp_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go/internal/xhttp"
+)
+
+func gzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestContentDecoder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		plain      = []byte(strings.Repeat("cloudcraft ", 64))
+		compressed = gzipData(t, plain)
+		upper      = func(r io.Reader) (io.ReadCloser, error) {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+
+			return io.NopCloser(bytes.NewReader(bytes.ToUpper(data))), nil
+		}
+	)
+
+	tests := []struct {
+		name             string
+		decoders         map[string]xhttp.Decoder
+		giveEncoding     string
+		giveUncompressed bool
+		giveBody         []byte
+		wantAccept       string
+		wantBody         []byte
+		wantWireSize     int64
+		wantDecoded      bool
+		wantErr          bool
+	}{
+		{
+			name:         "gzip response",
+			decoders:     map[string]xhttp.Decoder{"gzip": xhttp.DecodeGzip},
+			giveEncoding: "gzip",
+			giveBody:     compressed,
+			wantAccept:   "gzip",
+			wantBody:     plain,
+			wantWireSize: int64(len(compressed)),
+			wantDecoded:  true,
+		},
+		{
+			name:        "Uncompressed response",
+			decoders:    map[string]xhttp.Decoder{"gzip": xhttp.DecodeGzip},
+			giveBody:    plain,
+			wantAccept:  "gzip",
+			wantBody:    plain,
+			wantDecoded: false,
+		},
+		{
+			name:         "Empty gzip response",
+			decoders:     map[string]xhttp.Decoder{"gzip": xhttp.DecodeGzip},
+			giveEncoding: "gzip",
+			giveBody:     []byte{},
+			wantAccept:   "gzip",
+			wantBody:     []byte{},
+			wantDecoded:  true,
+		},
+		{
+			name:         "Corrupt gzip response",
+			decoders:     map[string]xhttp.Decoder{"gzip": xhttp.DecodeGzip},
+			giveEncoding: "gzip",
+			giveBody:     []byte("not gzip"),
+			wantAccept:   "gzip",
+			wantDecoded:  true,
+			wantErr:      true,
+		},
+		{
+			name: "Custom decoder",
+			decoders: map[string]xhttp.Decoder{
+				"gzip": xhttp.DecodeGzip,
+				"X-Up": upper,
+			},
+			giveEncoding: "x-up",
+			giveBody:     []byte("hello"),
+			wantAccept:   "x-up, gzip",
+			wantBody:     []byte("HELLO"),
+			wantWireSize: 5,
+			wantDecoded:  true,
+		},
+		{
+			name:         "Unsupported encoding",
+			decoders:     map[string]xhttp.Decoder{"gzip": xhttp.DecodeGzip},
+			giveEncoding: "br",
+			giveBody:     compressed,
+			wantAccept:   "gzip",
+			wantBody:     compressed,
+			wantDecoded:  false,
+		},
+		{
+			name:             "Already decompressed by the transport",
+			decoders:         map[string]xhttp.Decoder{"gzip": xhttp.DecodeGzip},
+			giveEncoding:     "gzip",
+			giveUncompressed: true,
+			giveBody:         plain,
+			wantAccept:       "gzip",
+			wantBody:         plain,
+			wantDecoded:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			decoder := xhttp.NewContentDecoder(tt.decoders)
+
+			if got := decoder.AcceptEncoding(); got != tt.wantAccept {
+				t.Fatalf("ContentDecoder.AcceptEncoding() = %q, want %q", got, tt.wantAccept)
+			}
+
+			header := http.Header{}
+			if tt.giveEncoding != "" {
+				header.Set("Content-Encoding", tt.giveEncoding)
+			}
+
+			resp := &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        header,
+				Body:          io.NopCloser(bytes.NewReader(tt.giveBody)),
+				ContentLength: int64(len(tt.giveBody)),
+				Uncompressed:  tt.giveUncompressed,
+			}
+			defer resp.Body.Close()
+
+			decoder.Decode(resp)
+
+			got, err := io.ReadAll(resp.Body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			decoded, ok := resp.Body.(*xhttp.DecodedBody)
+			if ok != tt.wantDecoded {
+				t.Fatalf("ContentDecoder.Decode() decoded = %v, want %v", ok, tt.wantDecoded)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !bytes.Equal(got, tt.wantBody) {
+				t.Fatalf("ContentDecoder.Decode() body = %q, want %q", got, tt.wantBody)
+			}
+
+			if !ok {
+				return
+			}
+
+			if decoded.WireSize() != tt.wantWireSize {
+				t.Fatalf("DecodedBody.WireSize() = %d, want %d", decoded.WireSize(), tt.wantWireSize)
+			}
+
+			if resp.Header.Get("Content-Encoding") != "" || resp.ContentLength != -1 || !resp.Uncompressed {
+				t.Fatal("ContentDecoder.Decode() did not clear the encoding of the decoded response")
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package xhttp
+
+import (
+	"errors"
+	"net"
+)
+
+// IsUnsentError reports whether err was returned before a request could reach
+// the server, such as when resolving the host or dialing it fails. Requests
+// that failed this way can be retried without risk of being processed twice.
+func IsUnsentError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package xhttp_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go/internal/xhttp"
+)
+
+func TestIsUnsentError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		give error
+		want bool
+	}{
+		{
+			name: "Dial error",
+			give: &url.Error{
+				Op:  "Post",
+				URL: "https://api.cloudcraft.co/blueprint",
+				Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			},
+			want: true,
+		},
+		{
+			name: "DNS error",
+			give: fmt.Errorf("wrapped: %w", &net.DNSError{Err: "no such host", Name: "api.cloudcraft.co"}),
+			want: true,
+		},
+		{
+			name: "Read error",
+			give: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")},
+			want: false,
+		},
+		{
+			name: "Unexpected EOF",
+			give: &url.Error{Op: "Post", URL: "https://api.cloudcraft.co/blueprint", Err: io.ErrUnexpectedEOF},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := xhttp.IsUnsentError(tt.give); got != tt.want {
+				t.Errorf("IsUnsentError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
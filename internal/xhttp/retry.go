@@ -2,10 +2,13 @@ package xhttp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,6 +25,10 @@ const (
 	// DefaultMaxRetryDelay is the default maximum duration to wait before
 	// retrying a request.
 	DefaultMaxRetryDelay time.Duration = 30 * time.Second
+
+	// DefaultMaxRetryAfter is the default maximum duration honored from a
+	// Retry-After header.
+	DefaultMaxRetryAfter time.Duration = 5 * time.Minute
 )
 
 // RetryPolicy defines a policy for retrying HTTP requests.
@@ -38,6 +45,15 @@ type RetryPolicy struct {
 
 	// MaxRetryDelay is the maximum duration to wait before retrying a request.
 	MaxRetryDelay time.Duration
+
+	// MaxRetryAfter is the maximum duration honored from a Retry-After
+	// header. Longer durations are capped. If zero, DefaultMaxRetryAfter is
+	// used.
+	MaxRetryAfter time.Duration
+
+	// Jitter randomizes the computed delay to prevent the "thundering herd"
+	// problem. If nil, the delay is randomized by up to ±10%.
+	Jitter func(time.Duration) time.Duration
 }
 
 // Wait calculates the time to wait before the next retry attempt and blocks
@@ -48,24 +64,90 @@ type RetryPolicy struct {
 // If the context is canceled before the wait is over, Wait returns the
 // context's error.
 func (p *RetryPolicy) Wait(ctx context.Context, attempt int) error {
+	return Sleep(ctx, p.Backoff(attempt, nil))
+}
+
+// Backoff returns the duration to wait before the retry that follows the given
+// attempt.
+//
+// If resp is a 429 or 503 response carrying a valid Retry-After header, the
+// delay requested by the server is used instead of the exponential backoff,
+// capped by MaxRetryAfter.
+func (p *RetryPolicy) Backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusServiceUnavailable) {
+		if delay, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			maxRetryAfter := p.MaxRetryAfter
+			if maxRetryAfter <= 0 {
+				maxRetryAfter = DefaultMaxRetryAfter
+			}
+
+			return min(delay, maxRetryAfter)
+		}
+	}
+
 	waitTime := float64(p.MinRetryDelay) * math.Pow(_backoffFactor, float64(attempt))
 
 	if time.Duration(waitTime) > p.MaxRetryDelay {
 		waitTime = float64(p.MaxRetryDelay)
 	}
 
+	if p.Jitter != nil {
+		return max(p.Jitter(time.Duration(waitTime)), 0)
+	}
+
 	jitter := (rand.Float64()*2 - 1) * _jitterFactor * waitTime //nolint:gosec // we don't need cryptographic randomness
 
-	waitTimeWithJitter := time.Duration(waitTime + jitter)
+	return time.Duration(waitTime + jitter)
+}
+
+// Sleep blocks for the given duration or until the context is canceled, in
+// which case it returns the context's error.
+func Sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
 
 	select {
 	case <-ctx.Done():
 		return fmt.Errorf("%w", ctx.Err())
-	case <-time.After(waitTimeWithJitter):
+	case <-timer.C:
 		return nil
 	}
 }
 
+// ParseRetryAfter parses the value of a Retry-After header, given either as a
+// number of seconds or as an HTTP date, and returns the duration to wait
+// relative to now. It reports false if the value is empty or invalid. Values
+// too long for a time.Duration are clamped to the longest one.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	// Numbers too large for an int64 are clamped by ParseInt.
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		// Durations too long for a time.Duration are clamped rather than
+		// overflowing, so that they are still capped by MaxRetryAfter.
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return math.MaxInt64, true
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
 // DefaultIsRetryable defines the default logic to determine if a request should
 // be retried.
 //
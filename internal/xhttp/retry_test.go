@@ -3,6 +3,7 @@ package xhttp_test
 import (
 	"context"
 	"errors"
+	"math"
 	"net/http"
 	"testing"
 	"time"
@@ -60,7 +61,7 @@ func TestRetryPolicy_Wait(t *testing.T) {
 			contextFunc: func() (context.Context, context.CancelFunc) {
 				return context.WithTimeout(context.Background(), 10*time.Second)
 			},
-			expectedWaitMax: 1 * time.Second,
+			expectedWaitMax: 1100 * time.Millisecond, // Delay plus 10% jitter.
 			expectedErr:     nil,
 		},
 	}
@@ -191,3 +192,169 @@ func TestDefaultIsRetryable(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	policy := xhttp.RetryPolicy{
+		IsRetryable:   xhttp.DefaultIsRetryable,
+		MaxRetries:    3,
+		MinRetryDelay: 1 * time.Second,
+		MaxRetryDelay: 8 * time.Second,
+		MaxRetryAfter: 1 * time.Minute,
+		Jitter:        func(d time.Duration) time.Duration { return d },
+	}
+
+	tests := []struct {
+		name    string
+		attempt int
+		resp    *http.Response
+		want    time.Duration
+	}{
+		{
+			name:    "Exponential backoff without response",
+			attempt: 2,
+			resp:    nil,
+			want:    4 * time.Second,
+		},
+		{
+			name:    "Exponential backoff capped by maximum delay",
+			attempt: 10,
+			resp:    nil,
+			want:    8 * time.Second,
+		},
+		{
+			name:    "Retry-After in seconds on 429",
+			attempt: 0,
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": []string{"20"}},
+			},
+			want: 20 * time.Second,
+		},
+		{
+			name:    "Retry-After capped by maximum Retry-After",
+			attempt: 0,
+			resp: &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Retry-After": []string{"3600"}},
+			},
+			want: 1 * time.Minute,
+		},
+		{
+			name:    "Retry-After overflowing a duration capped by maximum Retry-After",
+			attempt: 0,
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": []string{"99999999999999"}},
+			},
+			want: 1 * time.Minute,
+		},
+		{
+			name:    "Retry-After ignored on 502",
+			attempt: 1,
+			resp: &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Header:     http.Header{"Retry-After": []string{"20"}},
+			},
+			want: 2 * time.Second,
+		},
+		{
+			name:    "Invalid Retry-After",
+			attempt: 1,
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": []string{"soon"}},
+			},
+			want: 2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := policy.Backoff(tt.attempt, tt.resp); got != tt.want {
+				t.Errorf("Backoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.November, 8, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		give   string
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name:   "Seconds",
+			give:   "120",
+			want:   2 * time.Minute,
+			wantOK: true,
+		},
+		{
+			name:   "HTTP date",
+			give:   "Wed, 08 Nov 2023 14:00:30 GMT",
+			want:   30 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "HTTP date in the past",
+			give:   "Wed, 08 Nov 2023 13:00:00 GMT",
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:   "Empty value",
+			give:   "",
+			wantOK: false,
+		},
+		{
+			name:   "Seconds overflowing a duration",
+			give:   "99999999999999",
+			want:   math.MaxInt64,
+			wantOK: true,
+		},
+		{
+			name:   "Seconds overflowing an integer",
+			give:   "99999999999999999999",
+			want:   math.MaxInt64,
+			wantOK: true,
+		},
+		{
+			name:   "Negative seconds",
+			give:   "-5",
+			wantOK: false,
+		},
+		{
+			name:   "Invalid value",
+			give:   "tomorrow",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := xhttp.ParseRetryAfter(tt.give, now)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseRetryAfter() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -60,3 +60,22 @@ func GetDurationEnv(key string, fallback time.Duration) time.Duration {
 
 	return durationValue
 }
+
+// GetBoolEnv returns the boolean value of the environment variable named by
+// the key, as parsed by strconv.ParseBool.
+//
+// If the variable is present in the environment the value (which may be empty)
+// or if the variable is unset, a fallback value is returned.
+func GetBoolEnv(key string, fallback bool) bool {
+	value, found := os.LookupEnv(key)
+	if !found || value == "" {
+		return fallback
+	}
+
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+
+	return boolValue
+}
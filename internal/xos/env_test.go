@@ -146,3 +146,48 @@ func TestGetDurationEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBoolEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		envVal   string
+		fallback bool
+		want     bool
+	}{
+		{
+			name:     "non-existent variable with a fallback",
+			key:      "SOMETHING_THAT_DOES_NOT_EXIST",
+			fallback: true,
+			envVal:   "",
+			want:     true,
+		},
+		{
+			name:     "existent variable with a valid boolean value",
+			key:      "SOME_EXISTENT_VARIABLE",
+			fallback: false,
+			envVal:   "1",
+			want:     true,
+		},
+		{
+			name:     "existent variable with an invalid boolean value",
+			key:      "SOME_EXISTENT_VARIABLE",
+			fallback: true,
+			envVal:   "not-a-boolean",
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests { //nolint:paralleltest // Test is not safe to run in parallel.
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.key, tt.envVal)
+			defer os.Unsetenv(tt.key)
+
+			got := xos.GetBoolEnv(tt.key, tt.fallback)
+
+			if got != tt.want {
+				t.Errorf("GetBoolEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
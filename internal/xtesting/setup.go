@@ -18,6 +18,19 @@ const _envAPIKey string = "CLOUDCRAFT_TEST_API_KEY"
 func SetupMockClient(t *testing.T, endpoint *url.URL) *cloudcraft.Client {
 	t.Helper()
 
+	return SetupMockClientWithConfig(t, endpoint, nil)
+}
+
+// SetupMockClientWithConfig sets up a test API client for unit tests against a
+// mock version of the Cloudcraft API, letting the caller adjust the Config
+// before the client is created. If configure is nil, the Config is used as is.
+func SetupMockClientWithConfig(
+	t *testing.T,
+	endpoint *url.URL,
+	configure func(cfg *cloudcraft.Config),
+) *cloudcraft.Client {
+	t.Helper()
+
 	cfg := &cloudcraft.Config{
 		Scheme: endpoint.Scheme,
 		Host:   endpoint.Hostname(),
@@ -26,6 +39,10 @@ func SetupMockClient(t *testing.T, endpoint *url.URL) *cloudcraft.Client {
 		Key:    "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
 	}
 
+	if configure != nil {
+		configure(cfg)
+	}
+
 	client, err := cloudcraft.NewClient(cfg)
 	if err != nil {
 		t.Fatalf("failed to create client for mock tests: %v", err)
@@ -15,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DataDog/cloudcraft-go"
 	"github.com/DataDog/cloudcraft-go/internal/xerrors"
 )
 
@@ -92,3 +93,25 @@ func ParseTime(t *testing.T, str string) time.Time {
 
 	return parsedTime
 }
+
+// NormalizeResponse returns a copy of the given response with the fields that
+// vary from one request to another, such as the Date header and durations,
+// cleared, so that it can be compared with an expected response.
+func NormalizeResponse(resp *cloudcraft.Response) *cloudcraft.Response {
+	if resp == nil {
+		return nil
+	}
+
+	normalized := *resp
+	normalized.Header = resp.Header.Clone()
+
+	normalized.Header.Del("Date")
+
+	normalized.BodyStats.ReadDuration = 0
+	normalized.BodyStats.DecodeDuration = 0
+
+	// The URL and the durations of a call change with every mock server.
+	normalized.CallStats = cloudcraft.CallStats{}
+
+	return &normalized
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces secrets in logs and formatted values.
+const redactedValue string = "[REDACTED]"
+
+// redact returns redactedValue if secret is set, or an empty string otherwise,
+// so that logs still tell whether a secret was provided.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return redactedValue
+}
+
+// sensitiveHeaderPattern matches the names of the headers that hold secrets,
+// such as credentials, cookies and tokens, whether they are set by the client
+// or by middleware.
+var sensitiveHeaderPattern = regexp.MustCompile(`(?i)auth|cookie|token|secret|password|session|signature|api-?key`)
+
+// redactHeader returns a copy of header that is safe to log, with the values of
+// sensitive headers redacted. The scheme of an Authorization header is kept.
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+
+	for key, values := range redacted {
+		if !sensitiveHeaderPattern.MatchString(key) {
+			continue
+		}
+
+		for i, value := range values {
+			if value == "" {
+				continue
+			}
+
+			scheme, _, found := strings.Cut(value, " ")
+			if found && strings.HasSuffix(key, "Authorization") {
+				values[i] = scheme + " " + redactedValue
+			} else {
+				values[i] = redactedValue
+			}
+		}
+	}
+
+	return redacted
+}
+
+// enabled reports whether the client logs events at the given level.
+func (c *Client) enabled(ctx context.Context, level slog.Level) bool {
+	return c.logger != nil && c.logger.Enabled(ctx, level)
+}
+
+// logRequest logs an attempt to send req.
+func (c *Client) logRequest(req *http.Request, attempt int) {
+	if !c.enabled(req.Context(), slog.LevelDebug) {
+		return
+	}
+
+	c.logger.LogAttrs(req.Context(), slog.LevelDebug, "cloudcraft: sending request",
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.Int("attempt", attempt),
+		slog.Any("header", redactHeader(req.Header)),
+	)
+}
+
+// logResponse logs the outcome of an attempt to send req.
+func (c *Client) logResponse(req *http.Request, attempt int, resp *http.Response, err error, elapsed time.Duration) {
+	if !c.enabled(req.Context(), slog.LevelDebug) {
+		return
+	}
+
+	if err != nil {
+		c.logger.LogAttrs(req.Context(), slog.LevelDebug, "cloudcraft: request attempt failed",
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.String()),
+			slog.Int("attempt", attempt),
+			slog.Duration("duration", elapsed),
+			slog.String("error", err.Error()),
+		)
+
+		return
+	}
+
+	c.logger.LogAttrs(req.Context(), slog.LevelDebug, "cloudcraft: received response",
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.Int("attempt", attempt),
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", elapsed),
+	)
+}
+
+// logRetry logs that req is retried after backoff.
+func (c *Client) logRetry(req *http.Request, attempt int, resp *http.Response, err error, backoff time.Duration) {
+	if !c.enabled(req.Context(), slog.LevelInfo) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.Int("attempt", attempt),
+		slog.Duration("backoff", backoff),
+	}
+
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	c.logger.LogAttrs(req.Context(), slog.LevelInfo, "cloudcraft: retrying request", attrs...)
+}
+
+// logCall logs the outcome of a call once it is done.
+func (c *Client) logCall(ctx context.Context, state *call, status int, err error) {
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+	}
+
+	if !c.enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("service", state.op.service),
+		slog.String("operation", state.op.name),
+		slog.Int("attempts", state.attempts),
+		slog.Duration("duration", time.Since(state.start)),
+	}
+
+	if status != 0 {
+		attrs = append(attrs, slog.Int("status", status))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+
+		c.logger.LogAttrs(ctx, level, "cloudcraft: request failed", attrs...)
+
+		return
+	}
+
+	c.logger.LogAttrs(ctx, level, "cloudcraft: request completed", attrs...)
+}
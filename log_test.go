@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+const (
+	_testAPIKey       = "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd="
+	_testClientSecret = "not-a-real-client-secret"
+	_testExternalID   = "not-a-real-external-id"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestClient_Logger(t *testing.T) {
+	t.Parallel()
+
+	var (
+		validTestData = xtesting.ReadFile(t, filepath.Join("tests/data/azure", "create-valid.json"))
+		requests      atomic.Int32
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		w.Write(validTestData)
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output syncBuffer
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		fastRetries(cfg)
+
+		cfg.Logger = slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	})
+
+	account := &cloudcraft.AzureAccount{
+		Name:           "Go SDK Test",
+		ApplicationID:  "app-id",
+		DirectoryID:    "dir-id",
+		SubscriptionID: "sub-id",
+		ClientSecret:   _testClientSecret,
+	}
+
+	// Idempotency keys make the POST request safe to retry.
+	ctx := cloudcraft.ContextWithIdempotencyKey(context.Background(), cloudcraft.NewIdempotencyKey())
+
+	if _, _, err = client.Azure.Create(ctx, account); err != nil {
+		t.Fatalf("Azure.Create() error = %v", err)
+	}
+
+	logs := output.String()
+
+	for _, want := range []string{
+		`"level":"DEBUG","msg":"cloudcraft: sending request"`,
+		`"level":"DEBUG","msg":"cloudcraft: received response"`,
+		`"level":"INFO","msg":"cloudcraft: retrying request"`,
+		`"level":"DEBUG","msg":"cloudcraft: request completed"`,
+		`"backoff":`,
+		`"Authorization":["Bearer [REDACTED]"]`,
+		`"service":"Azure","operation":"Create","attempts":2`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs do not contain %s:\n%s", want, logs)
+		}
+	}
+
+	if strings.Contains(logs, _testAPIKey) {
+		t.Fatalf("logs contain the API key:\n%s", logs)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cfg = cloudcraft.NewConfig(_testAPIKey)
+		aws = cloudcraft.AWSAccount{
+			Name:       "AWS account",
+			RoleARN:    "arn:aws:iam::123456789012:role/cloudcraft",
+			ExternalID: _testExternalID,
+		}
+		azure = cloudcraft.AzureAccount{
+			Name:         "Azure account",
+			ClientSecret: _testClientSecret,
+		}
+	)
+
+	tests := []struct {
+		name       string
+		give       any
+		wantSecret string
+		wantField  string
+	}{
+		{name: "Config", give: *cfg, wantSecret: _testAPIKey, wantField: "Key:[REDACTED]"},
+		{name: "Config pointer", give: cfg, wantSecret: _testAPIKey, wantField: "Key:[REDACTED]"},
+		{name: "AWS account", give: aws, wantSecret: _testExternalID, wantField: "ExternalID:[REDACTED]"},
+		{name: "AWS account pointer", give: &aws, wantSecret: _testExternalID, wantField: "ExternalID:[REDACTED]"},
+		{name: "Azure account", give: azure, wantSecret: _testClientSecret, wantField: "ClientSecret:[REDACTED]"},
+		{name: "Azure account pointer", give: &azure, wantSecret: _testClientSecret, wantField: "ClientSecret:[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+				got := fmt.Sprintf(format, tt.give)
+
+				if strings.Contains(got, tt.wantSecret) {
+					t.Fatalf("Sprintf(%q) leaks a secret: %s", format, got)
+				}
+			}
+
+			if got := fmt.Sprintf("%+v", tt.give); !strings.Contains(got, tt.wantField) {
+				t.Fatalf("Sprintf(%%+v) = %s, want it to contain %s", got, tt.wantField)
+			}
+
+			var buf bytes.Buffer
+
+			logger := slog.New(slog.NewTextHandler(&buf, nil))
+			logger.Info("test", "value", tt.give)
+
+			if strings.Contains(buf.String(), tt.wantSecret) {
+				t.Fatalf("slog leaks a secret: %s", buf.String())
+			}
+
+			if !strings.Contains(buf.String(), "=[REDACTED]") {
+				t.Fatalf("slog output = %s, want a redacted value", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metrics receives the measurements of the calls made by a Client, so they can
+// be exported to a monitoring system.
+//
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// RecordCall is called once for every call made to the Cloudcraft API,
+	// after its response body has been read or the call failed.
+	RecordCall(call CallMetrics)
+}
+
+// CallMetrics holds the measurements of a call made to the Cloudcraft API.
+type CallMetrics struct {
+	// Err is the error the call failed with, if any.
+	Err error
+
+	// Service is the Cloudcraft API service called, one of "AWS", "Azure",
+	// "Blueprint" or "User".
+	Service string
+
+	// Operation is the operation called, named after the method of the
+	// service, such as "Get" or "Snapshot".
+	Operation string
+
+	// Latency is the duration of the call, from the first attempt until the
+	// response body was read, retries included.
+	Latency time.Duration
+
+	// BytesIn is the number of bytes of the response body received from the
+	// network.
+	BytesIn int64
+
+	// BytesOut is the number of bytes of the request body.
+	BytesOut int64
+
+	// Attempts is the number of attempts made. Attempts beyond the first one
+	// are retries.
+	Attempts int
+
+	// StatusCode is the HTTP status code of the final response. It is zero
+	// if no response was received.
+	StatusCode int
+}
+
+// DefaultLatencyBuckets are the upper bounds of the latency histogram buckets
+// used by InMemoryMetrics when none are given.
+func DefaultLatencyBuckets() []time.Duration {
+	return []time.Duration{
+		10 * time.Millisecond,
+		25 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2500 * time.Millisecond,
+		5 * time.Second,
+		10 * time.Second,
+		30 * time.Second,
+	}
+}
+
+// InMemoryMetrics is a Metrics implementation that aggregates calls in memory,
+// per service and operation. It is meant for tests and for exporting to
+// monitoring systems through periodic snapshots.
+type InMemoryMetrics struct {
+	operations map[operationKey]*OperationMetrics
+	buckets    []time.Duration
+	mu         sync.Mutex
+}
+
+var _ Metrics = (*InMemoryMetrics)(nil)
+
+// operationKey identifies the metrics of an operation.
+type operationKey struct {
+	service   string
+	operation string
+}
+
+// OperationMetrics holds the aggregated measurements of the calls made to an
+// operation of the Cloudcraft API.
+type OperationMetrics struct {
+	// StatusCodes counts the calls by the status code of their final
+	// response. Calls that received no response are counted under 0.
+	StatusCodes map[int]int64
+
+	// Service is the Cloudcraft API service called.
+	Service string
+
+	// Operation is the operation called.
+	Operation string
+
+	// Latency is the distribution of the latency of the calls.
+	Latency Histogram
+
+	// Calls is the number of calls made.
+	Calls int64
+
+	// Errors is the number of calls that failed.
+	Errors int64
+
+	// Attempts is the number of attempts made, retries included.
+	Attempts int64
+
+	// Retries is the number of attempts made beyond the first one of each
+	// call.
+	Retries int64
+
+	// BytesIn is the number of bytes received.
+	BytesIn int64
+
+	// BytesOut is the number of bytes sent.
+	BytesOut int64
+}
+
+// Histogram is a cumulative distribution of durations.
+type Histogram struct {
+	// Buckets holds the number of observations less than or equal to each
+	// upper bound, in increasing order of bounds. Observations above the last
+	// bound are only counted in Count.
+	Buckets []Bucket
+
+	// Count is the number of observations.
+	Count int64
+
+	// Sum is the sum of all observations.
+	Sum time.Duration
+
+	// Min is the smallest observation.
+	Min time.Duration
+
+	// Max is the largest observation.
+	Max time.Duration
+}
+
+// Bucket is a bucket of a Histogram.
+type Bucket struct {
+	// UpperBound is the inclusive upper bound of the bucket.
+	UpperBound time.Duration
+
+	// Count is the number of observations less than or equal to UpperBound.
+	Count int64
+}
+
+// NewInMemoryMetrics returns a new InMemoryMetrics whose latency histograms
+// use the given bucket upper bounds. If no bounds are given,
+// DefaultLatencyBuckets is used.
+func NewInMemoryMetrics(buckets ...time.Duration) *InMemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets()
+	}
+
+	sorted := make([]time.Duration, len(buckets))
+	copy(sorted, buckets)
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &InMemoryMetrics{
+		operations: make(map[operationKey]*OperationMetrics),
+		buckets:    sorted,
+	}
+}
+
+// RecordCall implements the Metrics interface.
+func (m *InMemoryMetrics) RecordCall(call CallMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := operationKey{service: call.Service, operation: call.Operation}
+
+	op, ok := m.operations[key]
+	if !ok {
+		op = &OperationMetrics{
+			StatusCodes: make(map[int]int64),
+			Service:     call.Service,
+			Operation:   call.Operation,
+			Latency:     newHistogram(m.buckets),
+		}
+
+		m.operations[key] = op
+	}
+
+	op.Calls++
+	op.Attempts += int64(call.Attempts)
+	op.Retries += int64(max(call.Attempts-1, 0))
+	op.BytesIn += call.BytesIn
+	op.BytesOut += call.BytesOut
+	op.StatusCodes[call.StatusCode]++
+
+	if call.Err != nil {
+		op.Errors++
+	}
+
+	op.Latency.observe(call.Latency)
+}
+
+// Snapshot returns a copy of the metrics recorded so far, sorted by service
+// and operation.
+func (m *InMemoryMetrics) Snapshot() []OperationMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make([]OperationMetrics, 0, len(m.operations))
+
+	for _, op := range m.operations {
+		snapshot = append(snapshot, op.clone())
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Service != snapshot[j].Service {
+			return snapshot[i].Service < snapshot[j].Service
+		}
+
+		return snapshot[i].Operation < snapshot[j].Operation
+	})
+
+	return snapshot
+}
+
+// Operation returns a copy of the metrics recorded so far for the given
+// service and operation, and whether any call to it was recorded.
+func (m *InMemoryMetrics) Operation(service, operation string) (OperationMetrics, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, ok := m.operations[operationKey{service: service, operation: operation}]
+	if !ok {
+		return OperationMetrics{}, false
+	}
+
+	return op.clone(), true
+}
+
+// Reset discards the metrics recorded so far.
+func (m *InMemoryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.operations = make(map[operationKey]*OperationMetrics)
+}
+
+// clone returns a deep copy of op.
+func (op *OperationMetrics) clone() OperationMetrics {
+	clone := *op
+
+	clone.StatusCodes = make(map[int]int64, len(op.StatusCodes))
+	for code, count := range op.StatusCodes {
+		clone.StatusCodes[code] = count
+	}
+
+	clone.Latency.Buckets = make([]Bucket, len(op.Latency.Buckets))
+	copy(clone.Latency.Buckets, op.Latency.Buckets)
+
+	return clone
+}
+
+// newHistogram returns an empty Histogram with the given bucket upper bounds.
+func newHistogram(bounds []time.Duration) Histogram {
+	buckets := make([]Bucket, len(bounds))
+	for i, bound := range bounds {
+		buckets[i].UpperBound = bound
+	}
+
+	return Histogram{Buckets: buckets}
+}
+
+// observe adds an observation to the histogram.
+func (h *Histogram) observe(d time.Duration) {
+	if h.Count == 0 || d < h.Min {
+		h.Min = d
+	}
+
+	if d > h.Max {
+		h.Max = d
+	}
+
+	h.Count++
+	h.Sum += d
+
+	for i := range h.Buckets {
+		if d <= h.Buckets[i].UpperBound {
+			h.Buckets[i].Count++
+		}
+	}
+}
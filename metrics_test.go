@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestClient_Metrics(t *testing.T) {
+	t.Parallel()
+
+	var (
+		getData    = xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "get-valid.json"))
+		createData = xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "create-valid.json"))
+		gets       atomic.Int32
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusOK)
+			w.Write(createData)
+		case r.URL.Path == "/blueprint/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case gets.Add(1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write(getData)
+		}
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := cloudcraft.NewInMemoryMetrics()
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		fastRetries(cfg)
+
+		cfg.Metrics = metrics
+	})
+
+	ctx := context.Background()
+
+	if _, _, err = client.Blueprint.Get(ctx, "blueprint-id"); err != nil {
+		t.Fatalf("Blueprint.Get() error = %v", err)
+	}
+
+	if _, _, err = client.Blueprint.Get(ctx, "missing"); !errors.Is(err, cloudcraft.ErrNotFound) {
+		t.Fatalf("Blueprint.Get() error = %v, want %v", err, cloudcraft.ErrNotFound)
+	}
+
+	if _, _, err = client.Blueprint.Create(ctx, &cloudcraft.Blueprint{}); err != nil {
+		t.Fatalf("Blueprint.Create() error = %v", err)
+	}
+
+	snapshot := metrics.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("Snapshot() returned %d operations, want 2", len(snapshot))
+	}
+
+	create, get := snapshot[0], snapshot[1]
+
+	if create.Service != "Blueprint" || create.Operation != "Create" {
+		t.Fatalf("Snapshot()[0] = %s.%s, want Blueprint.Create", create.Service, create.Operation)
+	}
+
+	if create.Calls != 1 || create.BytesOut == 0 || create.BytesIn != int64(len(createData)) {
+		t.Fatalf("Blueprint.Create metrics = %+v", create)
+	}
+
+	if get.Service != "Blueprint" || get.Operation != "Get" {
+		t.Fatalf("Snapshot()[1] = %s.%s, want Blueprint.Get", get.Service, get.Operation)
+	}
+
+	wantStatusCodes := map[int]int64{http.StatusOK: 1, http.StatusNotFound: 1}
+	if !reflect.DeepEqual(get.StatusCodes, wantStatusCodes) {
+		t.Fatalf("Blueprint.Get status codes = %v, want %v", get.StatusCodes, wantStatusCodes)
+	}
+
+	if get.Calls != 2 || get.Errors != 1 || get.Attempts != 3 || get.Retries != 1 {
+		t.Fatalf("Blueprint.Get metrics = %+v", get)
+	}
+
+	if get.BytesIn != int64(len(getData)) || get.BytesOut != 0 {
+		t.Fatalf("Blueprint.Get bytes in = %d, out = %d", get.BytesIn, get.BytesOut)
+	}
+
+	if get.Latency.Count != 2 || get.Latency.Sum <= 0 || get.Latency.Min > get.Latency.Max {
+		t.Fatalf("Blueprint.Get latency = %+v", get.Latency)
+	}
+
+	// Snapshots are copies that are not affected by later calls.
+	get.StatusCodes[http.StatusTeapot] = 1
+
+	if op, _ := metrics.Operation("Blueprint", "Get"); op.StatusCodes[http.StatusTeapot] != 0 {
+		t.Fatal("Snapshot() shares its status codes with the metrics")
+	}
+
+	metrics.Reset()
+
+	if snapshot = metrics.Snapshot(); len(snapshot) != 0 {
+		t.Fatalf("Snapshot() after Reset() = %+v, want empty", snapshot)
+	}
+
+	if _, ok := metrics.Operation("Blueprint", "Get"); ok {
+		t.Fatal("Operation() after Reset() found metrics")
+	}
+}
+
+func TestInMemoryMetrics_Latency(t *testing.T) {
+	t.Parallel()
+
+	metrics := cloudcraft.NewInMemoryMetrics(time.Second, 100*time.Millisecond)
+
+	for _, latency := range []time.Duration{
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		500 * time.Millisecond,
+		2 * time.Second,
+	} {
+		metrics.RecordCall(cloudcraft.CallMetrics{
+			Service:    "User",
+			Operation:  "Me",
+			Latency:    latency,
+			Attempts:   1,
+			StatusCode: http.StatusOK,
+		})
+	}
+
+	op, ok := metrics.Operation("User", "Me")
+	if !ok {
+		t.Fatal("Operation() found no metrics")
+	}
+
+	want := cloudcraft.Histogram{
+		Buckets: []cloudcraft.Bucket{
+			{UpperBound: 100 * time.Millisecond, Count: 2},
+			{UpperBound: time.Second, Count: 3},
+		},
+		Count: 4,
+		Sum:   2650 * time.Millisecond,
+		Min:   50 * time.Millisecond,
+		Max:   2 * time.Second,
+	}
+
+	if !reflect.DeepEqual(op.Latency, want) {
+		t.Fatalf("Latency = %+v, want %+v", op.Latency, want)
+	}
+}
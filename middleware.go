@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"net/http"
+
+	"github.com/DataDog/cloudcraft-go/internal/xhttp"
+)
+
+// Middleware wraps an http.RoundTripper to observe or alter the requests sent
+// to the Cloudcraft API and the responses received from it.
+//
+// Middleware is applied to every attempt made by the client, retries
+// included, which makes it suitable for adding headers, logging, tracing or
+// fault injection.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as
+// HTTP round trippers.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements the http.RoundTripper interface for RoundTripperFunc.
+// The error returned by f is returned unchanged.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req) //nolint:wrapcheck // The adapter is transparent to the middleware chain.
+}
+
+// chain wraps transport with the given middleware. The first middleware in the
+// list is the outermost one, meaning it sees requests first and responses
+// last.
+func chain(transport http.RoundTripper, middleware []Middleware) http.RoundTripper {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			transport = middleware[i](transport)
+		}
+	}
+
+	return transport
+}
+
+// newHTTPClient returns the HTTP client used by the API client given a Config,
+// along with its innermost transport, beneath the debug transport and the
+// middleware, if the client built it. It is nil if the transport was supplied
+// by the caller, or is http.DefaultTransport, since it may be shared with
+// other parts of a program.
+//
+// The client supplied in the Config is copied rather than modified, so it can
+// be safely shared with other parts of a program. It fails if the TLS settings
+// of the Config cannot be applied.
+func newHTTPClient(cfg *Config) (*http.Client, http.RoundTripper, error) {
+	var (
+		httpClient *http.Client
+		owned      http.RoundTripper
+	)
+
+	if cfg.HTTPClient != nil {
+		clientCopy := *cfg.HTTPClient
+
+		httpClient = &clientCopy
+	} else {
+		httpClient = xhttp.NewClient(cfg.Timeout)
+		owned = httpClient.Transport
+	}
+
+	if cfg.Transport != nil {
+		httpClient.Transport = cfg.Transport
+		owned = nil
+	}
+
+	if httpClient.Transport == nil {
+		httpClient.Transport = http.DefaultTransport
+	}
+
+	if cfg.TLS != nil {
+		transport, err := applyTLS(httpClient.Transport, cfg.TLS)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		httpClient.Transport = transport
+		owned = transport
+	}
+
+	if debugEnabled(cfg) {
+		httpClient.Transport = newDumpTransport(httpClient.Transport, cfg)
+	}
+
+	httpClient.Transport = chain(httpClient.Transport, cfg.Middleware)
+
+	return httpClient, owned, nil
+}
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var (
+		validTestData = xtesting.ReadFile(t, filepath.Join("tests/data/user", "me-valid.json"))
+		mu            sync.Mutex
+		calls         []string
+		requests      int
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		attempt := requests
+		mu.Unlock()
+
+		if r.Header.Get("X-Test") != "outer" {
+			t.Errorf("X-Test header = %q, want %q", r.Header.Get("X-Test"), "outer")
+		}
+
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		w.Write(validTestData)
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := func(name string) cloudcraft.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return cloudcraft.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+
+				if name == "outer" {
+					req = req.Clone(req.Context())
+					req.Header.Set("X-Test", name)
+				}
+
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	cfg := &cloudcraft.Config{
+		Scheme: endpoint.Scheme,
+		Host:   endpoint.Hostname(),
+		Port:   endpoint.Port(),
+		Path:   cloudcraft.DefaultPath,
+		Key:    "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		Middleware: []cloudcraft.Middleware{
+			record("outer"),
+			record("inner"),
+		},
+	}
+
+	client, err := cloudcraft.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, _, err = client.User.Me(context.Background()); err != nil {
+		t.Fatalf("UserService.Me() error = %v", err)
+	}
+
+	want := []string{"outer", "inner", "outer", "inner"}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("middleware calls = %v, want %v", calls, want)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	validTestData := xtesting.ReadFile(t, filepath.Join("tests/data/user", "me-valid.json"))
+
+	var called bool
+
+	httpClient := &http.Client{}
+
+	cfg := cloudcraft.NewConfig("not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=")
+	cfg.HTTPClient = httpClient
+	cfg.Transport = cloudcraft.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+
+		recorder := httptest.NewRecorder()
+		recorder.WriteHeader(http.StatusOK)
+		recorder.Write(validTestData)
+
+		resp := recorder.Result()
+		resp.Request = req
+
+		return resp, nil
+	})
+
+	client, err := cloudcraft.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	user, _, err := client.User.Me(context.Background())
+	if err != nil {
+		t.Fatalf("UserService.Me() error = %v", err)
+	}
+
+	if !called {
+		t.Fatal("custom transport was not called")
+	}
+
+	if user == nil || user.Email == "" {
+		t.Fatalf("UserService.Me() = %+v, want a decoded user", user)
+	}
+
+	if httpClient.Transport != nil {
+		t.Fatal("NewClient modified the supplied HTTP client")
+	}
+}
+
+func TestRoundTripperFunc(t *testing.T) {
+	t.Parallel()
+
+	want := errors.New("connection refused")
+
+	rt := cloudcraft.RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, want
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.cloudcraft.co/user/me", http.NoBody)
+
+	// The error is returned as is, so that middleware can compare it.
+	if _, err := rt.RoundTrip(req); err != want { //nolint:errorlint // The identity of the error is tested.
+		t.Fatalf("RoundTrip() error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/meta"
+)
+
+// RequestOption customizes the calls made to the Cloudcraft API with a given
+// context. Options are attached to a context with ContextWithRequestOptions,
+// and apply to every call made with it, such as:
+//
+//	ctx = cloudcraft.ContextWithRequestOptions(ctx,
+//		cloudcraft.WithTimeout(10*time.Minute),
+//		cloudcraft.WithAttemptTimeout(5*time.Minute),
+//		cloudcraft.WithMaxRetries(1),
+//	)
+//
+//	image, _, err := client.AWS.Snapshot(ctx, id, "png", nil)
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the settings of the RequestOptions attached to a
+// context.
+type requestOptions struct {
+	// header holds the extra headers sent with every attempt.
+	header http.Header
+
+	// maxRetries overrides the maximum number of retries of the client if it
+	// is not nil.
+	maxRetries *int
+
+	// key overrides the API key given by the credentials of the client if it
+	// is not empty.
+	key string
+
+	// userAgent is appended to the User-Agent header if it is not empty.
+	userAgent string
+
+	// idempotencyKey is sent in the Idempotency-Key header if it is not empty.
+	idempotencyKey string
+
+	// timeout bounds the whole call, retries and backoff included.
+	timeout time.Duration
+
+	// attemptTimeout bounds every attempt, overriding Config.Timeout.
+	attemptTimeout time.Duration
+}
+
+// requestOptionsContextKey is the context key for request options.
+type requestOptionsContextKey struct{}
+
+// ContextWithRequestOptions returns a copy of ctx that carries the given
+// options, on top of the options already carried by ctx, if any. Later
+// options override earlier ones, except for WithHeader, which adds to the
+// headers already set.
+func ContextWithRequestOptions(ctx context.Context, opts ...RequestOption) context.Context {
+	options := requestOptionsFrom(ctx).clone()
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return context.WithValue(ctx, requestOptionsContextKey{}, options)
+}
+
+// WithTimeout sets the time limit of a whole call, retries and the backoff
+// between them included. It does not extend the time limit of single
+// attempts, set with Config.Timeout or WithAttemptTimeout.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithAttemptTimeout sets the time limit of every attempt of a call, including
+// reading the response body, in place of Config.Timeout. Use it for calls that
+// take longer than usual, such as snapshots of large accounts.
+func WithAttemptTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.attemptTimeout = timeout
+	}
+}
+
+// WithMaxRetries sets the maximum number of times a call is retried if it
+// fails, in place of Config.MaxRetries. Use zero to disable retries.
+func WithMaxRetries(retries int) RequestOption {
+	retries = max(retries, 0)
+
+	return func(o *requestOptions) {
+		o.maxRetries = &retries
+	}
+}
+
+// WithHeader adds a header sent with every attempt of a call. The headers set
+// by the client, such as Authorization and User-Agent, cannot be overridden;
+// see WithKey and WithUserAgent instead.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+
+		o.header.Add(key, value)
+	}
+}
+
+// WithUserAgent sets a suffix appended to the User-Agent header of a call, to
+// identify the application or job making it.
+func WithUserAgent(suffix string) RequestOption {
+	return func(o *requestOptions) {
+		o.userAgent = suffix
+	}
+}
+
+// WithKey sets the API key used to authenticate a call, in place of the one
+// given by the credentials of the client.
+func WithKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.key = key
+	}
+}
+
+// WithIdempotencyKey sets the idempotency key of a call, which allows it to be
+// retried after an ambiguous failure; see ContextWithIdempotencyKey.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// requestOptionsFrom returns the options carried by ctx. It never returns nil.
+func requestOptionsFrom(ctx context.Context) *requestOptions {
+	if options, ok := ctx.Value(requestOptionsContextKey{}).(*requestOptions); ok {
+		return options
+	}
+
+	return &requestOptions{}
+}
+
+// clone returns a copy of the options that can be modified without affecting
+// the original.
+func (o *requestOptions) clone() *requestOptions {
+	clone := *o
+	clone.header = o.header.Clone()
+
+	return &clone
+}
+
+// empty reports whether no option is set.
+func (o *requestOptions) empty() bool {
+	return len(o.header) == 0 &&
+		o.maxRetries == nil &&
+		o.key == "" &&
+		o.userAgent == "" &&
+		o.idempotencyKey == "" &&
+		o.timeout == 0 &&
+		o.attemptTimeout == 0
+}
+
+// retries returns the maximum number of retries of a call, given the one of
+// the client.
+func (o *requestOptions) retries(fallback int) int {
+	if o.maxRetries == nil {
+		return fallback
+	}
+
+	return *o.maxRetries
+}
+
+// userAgentHeader returns the User-Agent header of a call.
+func (o *requestOptions) userAgentHeader() string {
+	if o.userAgent == "" {
+		return meta.UserAgent
+	}
+
+	return meta.UserAgent + " " + o.userAgent
+}
+
+// httpClient returns the HTTP client used to send the attempts of a call,
+// given the one of the client.
+func (o *requestOptions) httpClient(fallback *http.Client) *http.Client {
+	if o.attemptTimeout <= 0 {
+		return fallback
+	}
+
+	client := *fallback
+	client.Timeout = o.attemptTimeout
+
+	return &client
+}
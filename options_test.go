@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+)
+
+func TestContextWithRequestOptions_Headers(t *testing.T) {
+	t.Parallel()
+
+	var gotHeader atomic.Value
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader.Store(r.Header.Clone())
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint: ts.URL,
+		Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := cloudcraft.ContextWithRequestOptions(context.Background(),
+		cloudcraft.WithHeader("X-Request-Source", "nightly-export"),
+		cloudcraft.WithHeader("Authorization", "Bearer overridden"),
+		cloudcraft.WithUserAgent("export-job/1.2"),
+	)
+
+	// Options are layered on top of the ones already carried by the context.
+	ctx = cloudcraft.ContextWithRequestOptions(ctx,
+		cloudcraft.WithKey("not-a-real-key-qNx7ZzX0ibwKcqYSdFBJeMm5Ymtd="),
+		cloudcraft.WithIdempotencyKey("idempotency-key"),
+	)
+
+	if _, _, err = client.Blueprint.Create(ctx, &cloudcraft.Blueprint{}); err != nil {
+		t.Fatalf("Blueprint.Create() error = %v", err)
+	}
+
+	header, _ := gotHeader.Load().(http.Header)
+
+	if got := header.Get("X-Request-Source"); got != "nightly-export" {
+		t.Errorf("X-Request-Source = %q, want %q", got, "nightly-export")
+	}
+
+	if got := header.Get("Authorization"); got != "Bearer not-a-real-key-qNx7ZzX0ibwKcqYSdFBJeMm5Ymtd=" {
+		t.Errorf("Authorization = %q, want the key set with WithKey", got)
+	}
+
+	if got := header.Get("User-Agent"); !strings.HasSuffix(got, " export-job/1.2") {
+		t.Errorf("User-Agent = %q, want the suffix set with WithUserAgent", got)
+	}
+
+	if got := header.Get(cloudcraft.IdempotencyKeyHeader); got != "idempotency-key" {
+		t.Errorf("%s = %q, want %q", cloudcraft.IdempotencyKeyHeader, got, "idempotency-key")
+	}
+}
+
+func TestContextWithRequestOptions_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	client, err := cloudcraft.NewClient(cloudcraft.NewConfig("not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd="))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := cloudcraft.ContextWithRequestOptions(context.Background(), cloudcraft.WithKey("too-short"))
+
+	if _, _, err = client.User.Me(ctx); !errors.Is(err, cloudcraft.ErrInvalidKey) {
+		t.Fatalf("User.Me() error = %v, want %v", err, cloudcraft.ErrInvalidKey)
+	}
+}
+
+func TestContextWithRequestOptions_Retries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		give      []cloudcraft.RequestOption
+		wantCalls int32
+	}{
+		{
+			name:      "Client default",
+			wantCalls: int32(cloudcraft.DefaultMaxRetries) + 1,
+		},
+		{
+			name:      "Retries disabled",
+			give:      []cloudcraft.RequestOption{cloudcraft.WithMaxRetries(0)},
+			wantCalls: 1,
+		},
+		{
+			name:      "More retries",
+			give:      []cloudcraft.RequestOption{cloudcraft.WithMaxRetries(5)},
+			wantCalls: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer ts.Close()
+
+			cfg := &cloudcraft.Config{
+				Endpoint: ts.URL,
+				Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+			}
+
+			fastRetries(cfg)
+
+			client, err := cloudcraft.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			ctx := cloudcraft.ContextWithRequestOptions(context.Background(), tt.give...)
+
+			if _, _, err = client.User.Me(ctx); err == nil {
+				t.Fatal("User.Me() error = nil, want an error")
+			}
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Fatalf("calls to the API = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestContextWithRequestOptions_Timeouts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		give    []cloudcraft.RequestOption
+		wantErr bool
+	}{
+		{
+			name:    "Attempt slower than the client timeout",
+			delay:   200 * time.Millisecond,
+			wantErr: true,
+		},
+		{
+			name:  "Attempt timeout extended",
+			delay: 200 * time.Millisecond,
+			give:  []cloudcraft.RequestOption{cloudcraft.WithAttemptTimeout(5 * time.Second)},
+		},
+		{
+			name:  "Call slower than the overall timeout",
+			delay: 200 * time.Millisecond,
+			give: []cloudcraft.RequestOption{
+				cloudcraft.WithAttemptTimeout(5 * time.Second),
+				cloudcraft.WithTimeout(50 * time.Millisecond),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(tt.delay):
+				case <-r.Context().Done():
+				}
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{}`))
+			}))
+			defer ts.Close()
+
+			cfg := &cloudcraft.Config{
+				Endpoint: ts.URL,
+				Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				Timeout:  50 * time.Millisecond,
+			}
+
+			fastRetries(cfg)
+
+			client, err := cloudcraft.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			ctx := cloudcraft.ContextWithRequestOptions(context.Background(), tt.give...)
+
+			_, _, err = client.User.Me(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("User.Me() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
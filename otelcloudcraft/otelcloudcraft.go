@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+// Package otelcloudcraft provides an OpenTelemetry implementation of the
+// cloudcraft.Tracer interface.
+//
+// It lives in its own module so that the Cloudcraft SDK itself does not depend
+// on OpenTelemetry.
+package otelcloudcraft
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/DataDog/cloudcraft-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer.
+const ScopeName string = "github.com/DataDog/cloudcraft-go/otelcloudcraft"
+
+// Option configures a Tracer.
+type Option func(t *Tracer)
+
+// WithTracerProvider sets the TracerProvider used to create spans. If not set,
+// the global TracerProvider is used.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(t *Tracer) {
+		t.provider = provider
+	}
+}
+
+// WithPropagator sets the propagator used to inject spans into the request
+// headers. If not set, the W3C Trace Context propagator is used.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(t *Tracer) {
+		t.propagator = propagator
+	}
+}
+
+// Tracer implements the cloudcraft.Tracer interface with OpenTelemetry.
+type Tracer struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+	tracer     trace.Tracer
+}
+
+var _ cloudcraft.Tracer = (*Tracer)(nil)
+
+// NewTracer returns a new Tracer given the provided options.
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{
+		provider:   otel.GetTracerProvider(),
+		propagator: propagation.TraceContext{},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	t.tracer = t.provider.Tracer(ScopeName)
+
+	return t
+}
+
+// Start implements the cloudcraft.Tracer interface.
+func (t *Tracer) Start(
+	ctx context.Context,
+	name string,
+	attrs ...cloudcraft.Attribute,
+) (context.Context, cloudcraft.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attrs)...),
+	)
+
+	return ctx, &Span{
+		ctx:        ctx,
+		span:       span,
+		propagator: t.propagator,
+	}
+}
+
+// Span implements the cloudcraft.Span interface with OpenTelemetry.
+type Span struct {
+	ctx        context.Context //nolint:containedctx // Needed to inject the span.
+	span       trace.Span
+	propagator propagation.TextMapPropagator
+}
+
+var _ cloudcraft.Span = (*Span)(nil)
+
+// SetAttributes implements the cloudcraft.Span interface.
+func (s *Span) SetAttributes(attrs ...cloudcraft.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+// Inject implements the cloudcraft.Span interface.
+func (s *Span) Inject(header http.Header) {
+	s.propagator.Inject(s.ctx, propagation.HeaderCarrier(header))
+}
+
+// End implements the cloudcraft.Span interface.
+func (s *Span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+
+	s.span.End()
+}
+
+// convert returns the OpenTelemetry equivalent of attrs.
+func convert(attrs []cloudcraft.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+
+	for _, attr := range attrs {
+		switch value := attr.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key, value))
+		case int:
+			kvs = append(kvs, attribute.Int(attr.Key, value))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key, value))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key, value))
+		case float64:
+			kvs = append(kvs, attribute.Float64(attr.Key, value))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, fmt.Sprint(value)))
+		}
+	}
+
+	return kvs
+}
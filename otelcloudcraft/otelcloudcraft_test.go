@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package otelcloudcraft_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/otelcloudcraft"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	t.Parallel()
+
+	var traceparent string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	)
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &cloudcraft.Config{
+		Scheme: endpoint.Scheme,
+		Host:   endpoint.Hostname(),
+		Port:   endpoint.Port(),
+		Path:   cloudcraft.DefaultPath,
+		Key:    "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		Tracer: otelcloudcraft.NewTracer(otelcloudcraft.WithTracerProvider(provider)),
+	}
+
+	client, err := cloudcraft.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = client.Blueprint.Get(context.Background(), "blueprint-id")
+	if !cloudcraft.IsNotFound(err) {
+		t.Fatalf("Blueprint.Get() error = %v, want a not found error", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+
+	attempt, call := spans[0], spans[1]
+
+	if call.Name() != "Blueprint.Get" || attempt.Name() != http.MethodGet {
+		t.Fatalf("span names = %q, %q", call.Name(), attempt.Name())
+	}
+
+	if attempt.Parent().SpanID() != call.SpanContext().SpanID() {
+		t.Fatal("attempt span is not a child of the call span")
+	}
+
+	if call.SpanKind() != trace.SpanKindClient || call.Status().Code != codes.Error {
+		t.Fatalf("call span kind = %v, status = %v", call.SpanKind(), call.Status())
+	}
+
+	wantAttrs := []attribute.KeyValue{
+		attribute.String(cloudcraft.AttributeService, "Blueprint"),
+		attribute.String(cloudcraft.AttributeOperation, "Get"),
+		attribute.String(cloudcraft.AttributeResourceID, "blueprint-id"),
+		attribute.Int(cloudcraft.AttributeAttempts, 1),
+		attribute.Int(cloudcraft.AttributeHTTPStatusCode, http.StatusNotFound),
+	}
+
+	gotAttrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range call.Attributes() {
+		gotAttrs[attr.Key] = attr.Value
+	}
+
+	for _, want := range wantAttrs {
+		if got, ok := gotAttrs[want.Key]; !ok || got != want.Value {
+			t.Errorf("call span attribute %q = %v, want %v", want.Key, got.Emit(), want.Value.Emit())
+		}
+	}
+
+	want := "00-" + attempt.SpanContext().TraceID().String() + "-" + attempt.SpanContext().SpanID().String() + "-01"
+	if traceparent != want {
+		t.Fatalf("traceparent = %q, want %q", traceparent, want)
+	}
+}
@@ -0,0 +1,258 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/ini"
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+	"github.com/DataDog/cloudcraft-go/internal/xos"
+)
+
+const (
+	// ErrProfileNotFound is returned when the profile selected is not defined
+	// in the config file, or when the config file does not exist.
+	ErrProfileNotFound xerrors.Error = "profile not found"
+
+	// ErrInvalidProfile is returned when the config file cannot be parsed or
+	// a profile holds an unknown key or an invalid value.
+	ErrInvalidProfile xerrors.Error = "invalid profile"
+)
+
+// errUnknownProfileKey is returned when a profile holds an unknown key.
+const errUnknownProfileKey xerrors.Error = "unknown key"
+
+// DefaultProfile is the name of the profile used when none is selected.
+const DefaultProfile string = "default"
+
+// DefaultConfigFile returns the path of the config file holding the profiles
+// when CLOUDCRAFT_CONFIG_FILE is not set: "cloudcraft/config" in the user
+// configuration directory, such as "~/.config/cloudcraft/config" on Linux.
+func DefaultConfigFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return filepath.Join(dir, "cloudcraft", "config"), nil
+}
+
+// NewConfigFromProfile returns a new Config from a named profile of the config
+// file, overridden by the values set in the environment.
+//
+// The config file is an INI file with one section per profile, which may set
+// any of the following keys:
+//
+//	[team-a]
+//	scheme = https
+//	host = cloudcraft.example.com
+//	port = 443
+//	path = /
+//	endpoint = https://cloudcraft.example.com/
+//	key = <API key>
+//	key_file = /run/secrets/cloudcraft
+//	max_retries = 3
+//	timeout = 2m
+//
+// The config file is read from CLOUDCRAFT_CONFIG_FILE, or from
+// DefaultConfigFile if it is not set. If name is empty, the profile named by
+// CLOUDCRAFT_PROFILE is used, or DefaultProfile if it is not set either; the
+// default profile is optional, while any other profile must exist.
+//
+// Values are taken, in order of precedence, from the environment, the profile
+// and finally the defaults. Use MergeConfig to give precedence to values set
+// explicitly by the caller.
+func NewConfigFromProfile(name string) (*Config, error) {
+	if name == "" {
+		name = xos.GetEnv(EnvProfile, "")
+	}
+
+	required := name != ""
+	if !required {
+		name = DefaultProfile
+	}
+
+	cfg := NewConfig("")
+
+	profile, err := readProfile(name)
+
+	switch {
+	case errors.Is(err, ErrProfileNotFound) && !required:
+	case err != nil:
+		return nil, err
+	default:
+		if err = cfg.applyProfile(profile); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidProfile, name, err)
+		}
+	}
+
+	cfg.applyEnv()
+
+	return cfg, nil
+}
+
+// MergeConfig returns a new Config with the fields set on explicit, and the
+// fields of base for the others, so that values set explicitly take precedence
+// over those loaded by NewConfigFromProfile or NewConfigFromEnv:
+//
+//	base, err := cloudcraft.NewConfigFromProfile("team-a")
+//	if err != nil {
+//		// Handle error.
+//	}
+//
+//	cfg := cloudcraft.MergeConfig(&cloudcraft.Config{Timeout: time.Minute}, base)
+//
+// A field is set when it does not hold its zero value, so explicit cannot
+// reset a field of base to its zero value. The Key and Credentials fields are
+// taken together from explicit when one of them is set on it, and so are the
+// Endpoint field and the Scheme, Host, Port and Path fields it overrides.
+func MergeConfig(explicit, base *Config) *Config {
+	var merged Config
+
+	if base != nil {
+		merged = *base
+	}
+
+	if explicit == nil {
+		return &merged
+	}
+
+	var (
+		from = reflect.ValueOf(explicit).Elem()
+		to   = reflect.ValueOf(&merged).Elem()
+	)
+
+	for i := 0; i < from.NumField(); i++ {
+		if field := from.Field(i); from.Type().Field(i).IsExported() && !field.IsZero() {
+			to.Field(i).Set(field)
+		}
+	}
+
+	if explicit.Key != "" || explicit.Credentials != nil {
+		merged.Key, merged.Credentials = explicit.Key, explicit.Credentials
+	}
+
+	if explicit.Endpoint == "" &&
+		(explicit.Scheme != "" || explicit.Host != "" || explicit.Port != "" || explicit.Path != "") {
+		merged.Endpoint = ""
+	}
+
+	return &merged
+}
+
+// LoadEnvFile sets the environment variables defined in the dotenv file at
+// path, such as the ".env" file read by the Makefile. Variables already set in
+// the environment are left untouched, so that they take precedence.
+func LoadEnvFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer f.Close()
+
+	vars, err := ini.ParseEnv(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for key, value := range vars {
+		if _, found := os.LookupEnv(key); found {
+			continue
+		}
+
+		if err = os.Setenv(key, value); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+
+	return nil
+}
+
+// readProfile returns the keys of the named profile of the config file.
+func readProfile(name string) (map[string]string, error) {
+	path := xos.GetEnv(EnvConfigFile, "")
+	if path == "" {
+		var err error
+
+		if path, err = DefaultConfigFile(); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrProfileNotFound, name, err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w %q: %s does not exist", ErrProfileNotFound, name, path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	defer f.Close()
+
+	sections, err := ini.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidProfile, path, err)
+	}
+
+	profile, ok := sections[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q in %s", ErrProfileNotFound, name, path)
+	}
+
+	return profile, nil
+}
+
+// applyProfile sets the fields of the Config from the keys of a profile.
+func (c *Config) applyProfile(profile map[string]string) error {
+	for key, value := range profile {
+		switch key {
+		case "scheme":
+			c.Scheme = value
+		case "host":
+			c.Host = value
+		case "port":
+			c.Port = value
+		case "path":
+			c.Path = value
+		case "endpoint":
+			c.Endpoint = value
+		case "key":
+			c.Key = value
+		case "key_file":
+			c.Credentials = NewFileCredentials(value)
+		case "max_retries":
+			retries, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("max_retries: %w", err)
+			}
+
+			c.MaxRetries = retries
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("timeout: %w", err)
+			}
+
+			c.Timeout = timeout
+		default:
+			return fmt.Errorf("%w %q", errUnknownProfileKey, key)
+		}
+	}
+
+	// A key file replaces the key, whichever comes first in the profile.
+	if c.Credentials != nil {
+		c.Key = ""
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+)
+
+const _testProfiles = `
+[default]
+host = api.cloudcraft.co
+key = not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=
+
+[self-hosted]
+scheme = http
+host = cloudcraft.example.com
+port = 8080
+path = /api
+key = not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=
+max_retries = 5
+timeout = 30s
+
+[endpoint]
+endpoint = https://cloudcraft.example.com/api
+key = not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=
+
+[typo]
+hots = cloudcraft.example.com
+
+[invalid-timeout]
+timeout = soon
+`
+
+func TestNewConfigFromProfile(t *testing.T) { //nolint:paralleltest // Test is not safe to run in parallel.
+	path := filepath.Join(t.TempDir(), "config")
+
+	if err := os.WriteFile(path, []byte(_testProfiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		giveName string
+		env      map[string]string
+		want     cloudcraft.Config
+		wantErr  error
+	}{
+		{
+			name: "Default profile",
+			file: path,
+			want: cloudcraft.Config{
+				Scheme:     cloudcraft.DefaultScheme,
+				Host:       "api.cloudcraft.co",
+				Port:       cloudcraft.DefaultPort,
+				Path:       cloudcraft.DefaultPath,
+				Key:        "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				MaxRetries: cloudcraft.DefaultMaxRetries,
+				Timeout:    cloudcraft.DefaultTimeout,
+			},
+		},
+		{
+			name:     "Named profile",
+			file:     path,
+			giveName: "self-hosted",
+			want: cloudcraft.Config{
+				Scheme:     "http",
+				Host:       "cloudcraft.example.com",
+				Port:       "8080",
+				Path:       "/api",
+				Key:        "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				MaxRetries: 5,
+				Timeout:    30 * time.Second,
+			},
+		},
+		{
+			name: "Profile selected by the environment and overridden by it",
+			file: path,
+			env: map[string]string{
+				cloudcraft.EnvProfile: "self-hosted",
+				cloudcraft.EnvPort:    "9090",
+			},
+			want: cloudcraft.Config{
+				Scheme:     "http",
+				Host:       "cloudcraft.example.com",
+				Port:       "9090",
+				Path:       "/api",
+				Key:        "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				MaxRetries: 5,
+				Timeout:    30 * time.Second,
+			},
+		},
+		{
+			name:     "Profile endpoint overridden by the environment",
+			file:     path,
+			giveName: "endpoint",
+			env: map[string]string{
+				cloudcraft.EnvHost: "cloudcraft.internal",
+			},
+			want: cloudcraft.Config{
+				Scheme:     cloudcraft.DefaultScheme,
+				Host:       "cloudcraft.internal",
+				Port:       cloudcraft.DefaultPort,
+				Path:       cloudcraft.DefaultPath,
+				Key:        "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				MaxRetries: cloudcraft.DefaultMaxRetries,
+				Timeout:    cloudcraft.DefaultTimeout,
+			},
+		},
+		{
+			name:     "Profile endpoint overridden by the endpoint in the environment",
+			file:     path,
+			giveName: "endpoint",
+			env: map[string]string{
+				cloudcraft.EnvHost:     "cloudcraft.internal",
+				cloudcraft.EnvEndpoint: "https://cloudcraft.internal:8443/api",
+			},
+			want: cloudcraft.Config{
+				Scheme:     cloudcraft.DefaultScheme,
+				Host:       "cloudcraft.internal",
+				Port:       cloudcraft.DefaultPort,
+				Path:       cloudcraft.DefaultPath,
+				Endpoint:   "https://cloudcraft.internal:8443/api",
+				Key:        "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				MaxRetries: cloudcraft.DefaultMaxRetries,
+				Timeout:    cloudcraft.DefaultTimeout,
+			},
+		},
+		{
+			name: "Missing config file without a selected profile",
+			file: filepath.Join(t.TempDir(), "missing"),
+			want: cloudcraft.Config{
+				Scheme:     cloudcraft.DefaultScheme,
+				Host:       cloudcraft.DefaultHost,
+				Port:       cloudcraft.DefaultPort,
+				Path:       cloudcraft.DefaultPath,
+				MaxRetries: cloudcraft.DefaultMaxRetries,
+				Timeout:    cloudcraft.DefaultTimeout,
+			},
+		},
+		{
+			name:     "Missing config file with a selected profile",
+			file:     filepath.Join(t.TempDir(), "missing"),
+			giveName: "self-hosted",
+			wantErr:  cloudcraft.ErrProfileNotFound,
+		},
+		{
+			name:     "Missing profile",
+			file:     path,
+			giveName: "missing",
+			wantErr:  cloudcraft.ErrProfileNotFound,
+		},
+		{
+			name:     "Unknown key",
+			file:     path,
+			giveName: "typo",
+			wantErr:  cloudcraft.ErrInvalidProfile,
+		},
+		{
+			name:     "Invalid value",
+			file:     path,
+			giveName: "invalid-timeout",
+			wantErr:  cloudcraft.ErrInvalidProfile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{
+				cloudcraft.EnvScheme, cloudcraft.EnvHost, cloudcraft.EnvPort, cloudcraft.EnvPath, cloudcraft.EnvEndpoint,
+				cloudcraft.EnvAPIKey, cloudcraft.EnvAPIKeyFile, cloudcraft.EnvMaxRetries,
+				cloudcraft.EnvTimeout, cloudcraft.EnvDebug, cloudcraft.EnvProfile,
+			} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			t.Setenv(cloudcraft.EnvConfigFile, tt.file)
+
+			got, err := cloudcraft.NewConfigFromProfile(tt.giveName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewConfigFromProfile() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Fatalf("NewConfigFromProfile() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	if err := os.WriteFile(path, []byte("CLOUDCRAFT_HOST=cloudcraft.example.com\nCLOUDCRAFT_PORT=8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(cloudcraft.EnvPort, "9090")
+
+	// Unset the host while letting t.Setenv restore it after the test.
+	t.Setenv(cloudcraft.EnvHost, "")
+	os.Unsetenv(cloudcraft.EnvHost)
+
+	if err := cloudcraft.LoadEnvFile(path); err != nil {
+		t.Fatalf("LoadEnvFile() error = %v", err)
+	}
+
+	if got := os.Getenv(cloudcraft.EnvHost); got != "cloudcraft.example.com" {
+		t.Fatalf("%s = %q, want it loaded from the file", cloudcraft.EnvHost, got)
+	}
+
+	if got := os.Getenv(cloudcraft.EnvPort); got != "9090" {
+		t.Fatalf("%s = %q, want the environment to take precedence", cloudcraft.EnvPort, got)
+	}
+
+	if err := cloudcraft.LoadEnvFile(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("LoadEnvFile() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestMergeConfig(t *testing.T) {
+	t.Parallel()
+
+	keyFile := cloudcraft.NewFileCredentials("/run/secrets/cloudcraft")
+
+	base := &cloudcraft.Config{
+		Scheme:      cloudcraft.DefaultScheme,
+		Host:        cloudcraft.DefaultHost,
+		Port:        cloudcraft.DefaultPort,
+		Path:        cloudcraft.DefaultPath,
+		Endpoint:    "https://cloudcraft.example.com/api",
+		Credentials: keyFile,
+		MaxRetries:  5,
+		Timeout:     30 * time.Second,
+	}
+
+	tests := []struct {
+		name         string
+		giveExplicit *cloudcraft.Config
+		giveBase     *cloudcraft.Config
+		want         cloudcraft.Config
+	}{
+		{
+			name:     "Nothing set explicitly",
+			giveBase: base,
+			want:     *base,
+		},
+		{
+			name:         "Explicit fields take precedence",
+			giveExplicit: &cloudcraft.Config{Timeout: time.Minute, Debug: true},
+			giveBase:     base,
+			want: cloudcraft.Config{
+				Scheme:      cloudcraft.DefaultScheme,
+				Host:        cloudcraft.DefaultHost,
+				Port:        cloudcraft.DefaultPort,
+				Path:        cloudcraft.DefaultPath,
+				Endpoint:    "https://cloudcraft.example.com/api",
+				Credentials: keyFile,
+				MaxRetries:  5,
+				Timeout:     time.Minute,
+				Debug:       true,
+			},
+		},
+		{
+			name: "Explicit key replaces the credentials",
+			giveExplicit: &cloudcraft.Config{
+				Key: "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+			},
+			giveBase: base,
+			want: cloudcraft.Config{
+				Scheme:     cloudcraft.DefaultScheme,
+				Host:       cloudcraft.DefaultHost,
+				Port:       cloudcraft.DefaultPort,
+				Path:       cloudcraft.DefaultPath,
+				Endpoint:   "https://cloudcraft.example.com/api",
+				Key:        "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				MaxRetries: 5,
+				Timeout:    30 * time.Second,
+			},
+		},
+		{
+			name:         "Explicit host replaces the endpoint",
+			giveExplicit: &cloudcraft.Config{Host: "cloudcraft.internal"},
+			giveBase:     base,
+			want: cloudcraft.Config{
+				Scheme:      cloudcraft.DefaultScheme,
+				Host:        "cloudcraft.internal",
+				Port:        cloudcraft.DefaultPort,
+				Path:        cloudcraft.DefaultPath,
+				Credentials: keyFile,
+				MaxRetries:  5,
+				Timeout:     30 * time.Second,
+			},
+		},
+		{
+			name:         "No base",
+			giveExplicit: &cloudcraft.Config{Host: "cloudcraft.internal"},
+			want:         cloudcraft.Config{Host: "cloudcraft.internal"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := cloudcraft.MergeConfig(tt.giveExplicit, tt.giveBase)
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Fatalf("MergeConfig() = %+v, want %+v", *got, tt.want)
+			}
+
+			if got == tt.giveBase || got == tt.giveExplicit {
+				t.Fatal("MergeConfig() returned one of its arguments, want a new Config")
+			}
+		})
+	}
+}
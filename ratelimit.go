@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+)
+
+// ErrInvalidRateLimit is returned when a Config is created with a negative
+// rate limit or burst size.
+const ErrInvalidRateLimit xerrors.Error = "invalid rate limit; rate and burst cannot be negative"
+
+// RateLimiterStats holds statistics about the time requests spent waiting on
+// the client-side rate limiter.
+type RateLimiterStats struct {
+	// Requests is the number of attempts that went through the rate limiter.
+	Requests int64
+
+	// Delayed is the number of attempts that had to wait before being sent.
+	Delayed int64
+
+	// TotalWait is the total time attempts spent waiting.
+	TotalWait time.Duration
+
+	// MaxWait is the longest time a single attempt spent waiting.
+	MaxWait time.Duration
+}
+
+// RateLimiterStats returns statistics about the time requests spent waiting on
+// the client-side rate limiter. It returns zero values if Config.RateLimit is
+// not set.
+func (c *Client) RateLimiterStats() RateLimiterStats {
+	if c.limiter == nil {
+		return RateLimiterStats{}
+	}
+
+	stats := c.limiter.Stats()
+
+	return RateLimiterStats{
+		Requests:  stats.Requests,
+		Delayed:   stats.Delayed,
+		TotalWait: stats.TotalWait,
+		MaxWait:   stats.MaxWait,
+	}
+}
+
+// resetEpochThreshold is the smallest reset value of a rate-limit header read
+// as a Unix time rather than as a number of seconds to wait.
+const resetEpochThreshold int64 = 1_000_000_000
+
+// RateLimit describes the request quota of an API key, as reported by the
+// rate-limit headers of a response from the Cloudcraft API.
+type RateLimit struct {
+	// Reset is the time at which the quota is restored. It is zero if the
+	// response does not report it.
+	Reset time.Time
+
+	// Limit is the maximum number of requests allowed in the current window.
+	Limit int
+
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+}
+
+// parseRateLimit returns the RateLimit reported by header, or nil if it holds
+// no valid rate-limit headers.
+//
+// Both the standard RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers and their X-RateLimit- counterparts are supported, the former taking
+// precedence. Reset values are read as a number of seconds relative to now,
+// unless they are large enough to be a Unix time.
+func parseRateLimit(header http.Header, now time.Time) *RateLimit {
+	for _, prefix := range []string{"RateLimit-", "X-RateLimit-"} {
+		limit, limitOK := parseRateLimitValue(header.Get(prefix + "Limit"))
+		remaining, remainingOK := parseRateLimitValue(header.Get(prefix + "Remaining"))
+
+		if !limitOK || !remainingOK {
+			continue
+		}
+
+		rateLimit := &RateLimit{
+			Limit:     int(limit),
+			Remaining: int(remaining),
+		}
+
+		if reset, ok := parseRateLimitValue(header.Get(prefix + "Reset")); ok {
+			if reset >= resetEpochThreshold {
+				rateLimit.Reset = time.Unix(reset, 0)
+			} else {
+				rateLimit.Reset = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+
+		return rateLimit
+	}
+
+	return nil
+}
+
+// parseRateLimitValue parses the value of a rate-limit header, a non-negative
+// integer. Only the first item of list values, such as "100, 100;w=60", is
+// read. It reports false if the value is empty or invalid.
+func parseRateLimitValue(value string) (int64, bool) {
+	value, _, _ = strings.Cut(value, ",")
+	value, _, _ = strings.Cut(value, ";")
+
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return n, true
+}
@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestClient_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	validTestData := xtesting.ReadFile(t, filepath.Join("tests/data/user", "me-valid.json"))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+		w.Write(validTestData)
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		cfg.RateLimit = 20
+		cfg.RateLimitBurst = 1
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, _, err = client.User.Me(context.Background()); err != nil {
+			t.Fatalf("UserService.Me() error = %v", err)
+		}
+	}
+
+	stats := client.RateLimiterStats()
+
+	if stats.Requests != 3 {
+		t.Errorf("RateLimiterStats().Requests = %d, want 3", stats.Requests)
+	}
+
+	if stats.Delayed != 2 {
+		t.Errorf("RateLimiterStats().Delayed = %d, want 2", stats.Delayed)
+	}
+
+	if stats.TotalWait <= 0 {
+		t.Errorf("RateLimiterStats().TotalWait = %v, want > 0", stats.TotalWait)
+	}
+
+	// With a rate of one request every 10 seconds, the next request has to
+	// wait longer than the context allows.
+	client = xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		cfg.RateLimit = 0.1
+	})
+
+	if _, _, err = client.User.Me(context.Background()); err != nil {
+		t.Fatalf("UserService.Me() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, _, err = client.User.Me(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("UserService.Me() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClient_RateLimiterStats_Disabled(t *testing.T) {
+	t.Parallel()
+
+	client, err := cloudcraft.NewClient(cloudcraft.NewConfig("not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd="))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if stats := client.RateLimiterStats(); stats != (cloudcraft.RateLimiterStats{}) {
+		t.Fatalf("RateLimiterStats() = %+v, want zero value", stats)
+	}
+}
+
+func TestResponse_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   *cloudcraft.RateLimit
+		// wantReset is the expected reset time, relative to the time of the
+		// call if wantRelative is set.
+		wantReset    time.Time
+		wantRelative time.Duration
+	}{
+		{
+			name:   "No rate-limit headers",
+			header: http.Header{},
+			want:   nil,
+		},
+		{
+			name: "Standard headers",
+			header: http.Header{
+				"Ratelimit-Limit":     {"100, 100;w=60"},
+				"Ratelimit-Remaining": {"42"},
+				"Ratelimit-Reset":     {"30"},
+			},
+			want:         &cloudcraft.RateLimit{Limit: 100, Remaining: 42},
+			wantRelative: 30 * time.Second,
+		},
+		{
+			name: "Prefixed headers with a Unix reset time",
+			header: http.Header{
+				"X-Ratelimit-Limit":     {"60"},
+				"X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset":     {"4102444800"},
+			},
+			want:      &cloudcraft.RateLimit{Limit: 60, Remaining: 0},
+			wantReset: time.Unix(4102444800, 0),
+		},
+		{
+			name: "Standard headers take precedence",
+			header: http.Header{
+				"Ratelimit-Limit":       {"100"},
+				"Ratelimit-Remaining":   {"99"},
+				"X-Ratelimit-Limit":     {"60"},
+				"X-Ratelimit-Remaining": {"59"},
+			},
+			want: &cloudcraft.RateLimit{Limit: 100, Remaining: 99},
+		},
+		{
+			name: "Invalid values",
+			header: http.Header{
+				"Ratelimit-Limit":     {"many"},
+				"Ratelimit-Remaining": {"-1"},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{}`))
+			}))
+			defer ts.Close()
+
+			client, err := cloudcraft.NewClient(&cloudcraft.Config{
+				Endpoint: ts.URL,
+				Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+			})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			start := time.Now()
+
+			_, resp, err := client.User.Me(context.Background())
+			if err != nil {
+				t.Fatalf("User.Me() error = %v", err)
+			}
+
+			got := resp.RateLimit
+
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("RateLimit = %+v, want %+v", got, tt.want)
+			}
+
+			if got == nil {
+				return
+			}
+
+			if got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining {
+				t.Fatalf("RateLimit = %+v, want %+v", got, tt.want)
+			}
+
+			wantReset := tt.wantReset
+			if tt.wantRelative > 0 {
+				wantReset = start.Add(tt.wantRelative)
+			}
+
+			if diff := got.Reset.Sub(wantReset); diff < 0 || diff > time.Since(start) {
+				t.Fatalf("RateLimit.Reset = %v, want %v", got.Reset, wantReset)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go/internal/ratelimit"
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+	"github.com/DataDog/cloudcraft-go/internal/xhttp"
+)
+
+// ErrInvalidRetryPolicy is returned when a Config is created with an invalid
+// RetryPolicy.
+const ErrInvalidRetryPolicy xerrors.Error = "invalid retry policy"
+
+const (
+	// DefaultMinRetryDelay is the default minimum duration to wait before
+	// retrying a request.
+	DefaultMinRetryDelay time.Duration = xhttp.DefaultMinRetryDelay
+
+	// DefaultMaxRetryDelay is the default maximum duration to wait before
+	// retrying a request.
+	DefaultMaxRetryDelay time.Duration = xhttp.DefaultMaxRetryDelay
+
+	// DefaultMaxRetryAfter is the default maximum duration honored from a
+	// Retry-After header sent by the Cloudcraft API.
+	DefaultMaxRetryAfter time.Duration = xhttp.DefaultMaxRetryAfter
+
+	// DefaultJitterFactor is the default fraction of the retry delay used to
+	// randomize it.
+	DefaultJitterFactor float64 = 0.1
+)
+
+// JitterFunc randomizes the delay computed by the exponential backoff of a
+// RetryPolicy. It is given the computed delay and returns the delay to wait.
+type JitterFunc func(delay time.Duration) time.Duration
+
+// RetryPolicy defines how failed requests to the Cloudcraft API are retried.
+//
+// Delays grow exponentially from MinRetryDelay to MaxRetryDelay and are
+// randomized by Jitter. When the API responds with 429 or 503 and a
+// Retry-After header, the delay it requests is honored instead, up to
+// MaxRetryAfter.
+//
+// The maximum number of retries is set with Config.MaxRetries.
+type RetryPolicy struct {
+	// IsRetryable determines whether a given response and error combination
+	// should be retried.
+	//
+	// If not set, DefaultIsRetryable is used.
+	IsRetryable func(*http.Response, error) bool
+
+	// Jitter randomizes the delay between retries.
+	//
+	// If not set, ProportionalJitter(DefaultJitterFactor) is used.
+	Jitter JitterFunc
+
+	// MinRetryDelay is the minimum duration to wait before retrying a request.
+	//
+	// If not set, the default value is 1 second.
+	MinRetryDelay time.Duration
+
+	// MaxRetryDelay is the maximum duration to wait before retrying a request,
+	// excluding delays requested through a Retry-After header.
+	//
+	// If not set, the default value is 30 seconds.
+	MaxRetryDelay time.Duration
+
+	// MaxRetryAfter is the maximum duration honored from a Retry-After
+	// header.
+	//
+	// If not set, the default value is 5 minutes.
+	MaxRetryAfter time.Duration
+}
+
+// Validate checks that the RetryPolicy is valid.
+func (p *RetryPolicy) Validate() error {
+	if p.MinRetryDelay < 0 || p.MaxRetryDelay < 0 || p.MaxRetryAfter < 0 {
+		return fmt.Errorf("%w: delays cannot be negative", ErrInvalidRetryPolicy)
+	}
+
+	if p.MinRetryDelay > 0 && p.MaxRetryDelay > 0 && p.MinRetryDelay > p.MaxRetryDelay {
+		return fmt.Errorf("%w: minimum delay is greater than maximum delay", ErrInvalidRetryPolicy)
+	}
+
+	return nil
+}
+
+// DefaultIsRetryable defines the default logic to determine if a request should
+// be retried.
+//
+// It returns true if an error occurs or the response status code indicates a
+// retry may be successful (e.g., 202, 408, 429, 502, 503, 504).
+func DefaultIsRetryable(resp *http.Response, err error) bool {
+	return xhttp.DefaultIsRetryable(resp, err)
+}
+
+// NoJitter is a JitterFunc that returns the delay unchanged.
+func NoJitter(delay time.Duration) time.Duration {
+	return delay
+}
+
+// FullJitter is a JitterFunc that returns a random delay between zero and the
+// computed delay.
+func FullJitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1)) //nolint:gosec // we don't need cryptographic randomness
+}
+
+// EqualJitter is a JitterFunc that returns a random delay between half the
+// computed delay and the computed delay.
+func EqualJitter(delay time.Duration) time.Duration {
+	half := delay / 2
+
+	return half + FullJitter(delay-half)
+}
+
+// ProportionalJitter returns a JitterFunc that randomizes the delay by up to
+// ±factor of its value. For example, a factor of 0.1 turns a delay of 10
+// seconds into a delay between 9 and 11 seconds.
+func ProportionalJitter(factor float64) JitterFunc {
+	return func(delay time.Duration) time.Duration {
+		jitter := (rand.Float64()*2 - 1) * factor * float64(delay) //nolint:gosec // we don't need cryptographic randomness
+
+		return max(time.Duration(float64(delay)+jitter), 0)
+	}
+}
+
+// newRetryPolicy returns the internal retry policy used by the client given a
+// Config.
+func newRetryPolicy(cfg *Config) *xhttp.RetryPolicy {
+	policy := &xhttp.RetryPolicy{
+		IsRetryable:   xhttp.DefaultIsRetryable,
+		MaxRetries:    cfg.MaxRetries,
+		MinRetryDelay: xhttp.DefaultMinRetryDelay,
+		MaxRetryDelay: xhttp.DefaultMaxRetryDelay,
+		MaxRetryAfter: xhttp.DefaultMaxRetryAfter,
+	}
+
+	if cfg.RetryPolicy == nil {
+		return policy
+	}
+
+	if cfg.RetryPolicy.IsRetryable != nil {
+		policy.IsRetryable = cfg.RetryPolicy.IsRetryable
+	}
+
+	if cfg.RetryPolicy.Jitter != nil {
+		policy.Jitter = cfg.RetryPolicy.Jitter
+	}
+
+	if cfg.RetryPolicy.MinRetryDelay > 0 {
+		policy.MinRetryDelay = cfg.RetryPolicy.MinRetryDelay
+	}
+
+	if cfg.RetryPolicy.MaxRetryDelay > 0 {
+		policy.MaxRetryDelay = cfg.RetryPolicy.MaxRetryDelay
+	}
+
+	if cfg.RetryPolicy.MaxRetryAfter > 0 {
+		policy.MaxRetryAfter = cfg.RetryPolicy.MaxRetryAfter
+	}
+
+	return policy
+}
+
+// RetryError is returned when a call to the Cloudcraft API still fails after
+// all the retries it was allowed. It records every attempt, and wraps both the
+// reason why retries stopped, ErrMaxRetriesExceeded or
+// ErrRetryBudgetExhausted, and the error of the last attempt, such as an
+// *APIError holding the last response, so that errors.Is and errors.As can be
+// used on either.
+type RetryError struct {
+	// Err is the error of the last attempt.
+	Err error
+
+	// Reason is the reason why the call was not retried further:
+	// ErrMaxRetriesExceeded or ErrRetryBudgetExhausted. If nil,
+	// ErrMaxRetriesExceeded is assumed.
+	Reason error
+
+	// Attempts holds the outcome of every attempt, in order.
+	Attempts []RetryAttempt
+}
+
+// RetryAttempt describes a failed attempt of a call.
+type RetryAttempt struct {
+	// Err is the transport error of the attempt. It is nil if a response was
+	// received.
+	Err error
+
+	// Backoff is the time waited before the next attempt. It is zero for the
+	// last attempt.
+	Backoff time.Duration
+
+	// StatusCode is the HTTP status code of the response. It is zero if no
+	// response was received.
+	StatusCode int
+}
+
+// newRetryAttempt returns the RetryAttempt describing an attempt that produced
+// the given response or error.
+func newRetryAttempt(resp *http.Response, err error, backoff time.Duration) RetryAttempt {
+	attempt := RetryAttempt{
+		Err:     err,
+		Backoff: backoff,
+	}
+
+	if resp != nil {
+		attempt.StatusCode = resp.StatusCode
+	}
+
+	return attempt
+}
+
+// Error implements the error interface for RetryError.
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%s after %d attempts: %v", e.reason(), len(e.Attempts), e.Err)
+}
+
+// Unwrap returns the reason why retries stopped and the error of the last
+// attempt.
+func (e *RetryError) Unwrap() []error {
+	return []error{e.reason(), e.Err}
+}
+
+// reason returns the reason why retries stopped.
+func (e *RetryError) reason() error {
+	if e.Reason == nil {
+		return ErrMaxRetriesExceeded
+	}
+
+	return e.Reason
+}
+
+// ErrInvalidRetryBudget is returned when a Config is created with an invalid
+// RetryBudget.
+const ErrInvalidRetryBudget xerrors.Error = "invalid retry budget"
+
+const (
+	// DefaultRetryBudgetTokens is the default maximum number of tokens of a
+	// RetryBudget.
+	DefaultRetryBudgetTokens float64 = 10
+
+	// DefaultRetryBudgetRatio is the default number of tokens refilled by a
+	// successful attempt.
+	DefaultRetryBudgetRatio float64 = 0.1
+)
+
+// RetryBudget caps the retries of a client as a whole, to prevent retry
+// amplification when the Cloudcraft API fails: without it, a burst of 500
+// failing calls with 3 retries each turns into 2000 requests.
+//
+// The budget is a token bucket shared by all calls. Every retry spends a
+// token, and every successful attempt refills TokenRatio tokens, up to
+// MaxTokens. When less than a token is left, failed attempts are not retried,
+// and a *RetryError wrapping ErrRetryBudgetExhausted is returned, with
+// CallStats.RetryBudgetExhausted set on the Response.
+type RetryBudget struct {
+	// MaxTokens is the maximum number of tokens in the budget, which is also
+	// the number of retries allowed in a row without any success.
+	//
+	// If not set, the default value is 10.
+	//
+	// This field is optional.
+	MaxTokens float64
+
+	// TokenRatio is the number of tokens refilled by every successful
+	// attempt. For example, 0.1 allows one retry for every ten successes.
+	//
+	// If not set, the default value is 0.1.
+	//
+	// This field is optional.
+	TokenRatio float64
+}
+
+// RetryBudgetStats holds statistics about the retry budget of a client.
+type RetryBudgetStats struct {
+	// Tokens is the number of tokens left in the budget.
+	Tokens float64
+
+	// Retries is the number of retries allowed by the budget.
+	Retries int64
+
+	// Denied is the number of retries denied because the budget was
+	// exhausted.
+	Denied int64
+}
+
+// Validate checks that the RetryBudget is valid.
+func (b *RetryBudget) Validate() error {
+	if b.MaxTokens < 0 || b.TokenRatio < 0 {
+		return fmt.Errorf("%w: values cannot be negative", ErrInvalidRetryBudget)
+	}
+
+	return nil
+}
+
+// newRetryBudget returns the retry budget of the client given a Config, or nil
+// if it is disabled.
+func newRetryBudget(cfg *Config) *ratelimit.Budget {
+	if cfg.RetryBudget == nil {
+		return nil
+	}
+
+	var (
+		maxTokens = cfg.RetryBudget.MaxTokens
+		ratio     = cfg.RetryBudget.TokenRatio
+	)
+
+	if maxTokens == 0 {
+		maxTokens = DefaultRetryBudgetTokens
+	}
+
+	if ratio == 0 {
+		ratio = DefaultRetryBudgetRatio
+	}
+
+	return ratelimit.NewBudget(maxTokens, ratio)
+}
+
+// depositRetryBudget refills the retry budget of the client if an attempt
+// succeeded, with a 2xx or 304 response, whether or not it is retried, such as
+// a 202 Accepted. Failures and attempts interrupted by the context of their
+// caller do not refill it.
+func (c *Client) depositRetryBudget(req *http.Request, resp *http.Response, err error) {
+	if c.retryBudget == nil || err != nil || req.Context().Err() != nil {
+		return
+	}
+
+	success := resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices
+	if success || resp.StatusCode == http.StatusNotModified {
+		c.retryBudget.Deposit()
+	}
+}
+
+// RetryBudgetStats returns statistics about the retry budget of the client.
+// It returns zero values if Config.RetryBudget is not set.
+func (c *Client) RetryBudgetStats() RetryBudgetStats {
+	if c.retryBudget == nil {
+		return RetryBudgetStats{}
+	}
+
+	stats := c.retryBudget.Stats()
+
+	return RetryBudgetStats{
+		Tokens:  stats.Tokens,
+		Retries: stats.Withdrawn,
+		Denied:  stats.Denied,
+	}
+}
@@ -0,0 +1,485 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestJitter(t *testing.T) {
+	t.Parallel()
+
+	const delay = 10 * time.Second
+
+	tests := []struct {
+		name    string
+		give    cloudcraft.JitterFunc
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "No jitter",
+			give:    cloudcraft.NoJitter,
+			wantMin: delay,
+			wantMax: delay,
+		},
+		{
+			name:    "Full jitter",
+			give:    cloudcraft.FullJitter,
+			wantMin: 0,
+			wantMax: delay,
+		},
+		{
+			name:    "Equal jitter",
+			give:    cloudcraft.EqualJitter,
+			wantMin: delay / 2,
+			wantMax: delay,
+		},
+		{
+			name:    "Proportional jitter",
+			give:    cloudcraft.ProportionalJitter(0.2),
+			wantMin: 8 * time.Second,
+			wantMax: 12 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			for i := 0; i < 100; i++ {
+				got := tt.give(delay)
+
+				if got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("jitter(%v) = %v, want between %v and %v", delay, got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		give    cloudcraft.RetryPolicy
+		wantErr bool
+	}{
+		{
+			name:    "Zero value",
+			give:    cloudcraft.RetryPolicy{},
+			wantErr: false,
+		},
+		{
+			name: "Valid delays",
+			give: cloudcraft.RetryPolicy{
+				MinRetryDelay: 100 * time.Millisecond,
+				MaxRetryDelay: 5 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Negative delay",
+			give: cloudcraft.RetryPolicy{
+				MinRetryDelay: -1 * time.Second,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Minimum delay greater than maximum delay",
+			give: cloudcraft.RetryPolicy{
+				MinRetryDelay: 10 * time.Second,
+				MaxRetryDelay: 5 * time.Second,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.give.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		validTestData = xtesting.ReadFile(t, filepath.Join("tests/data/user", "me-valid.json"))
+		requests      atomic.Int32
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		w.Write(validTestData)
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var classified atomic.Int32
+
+	cfg := &cloudcraft.Config{
+		Scheme: endpoint.Scheme,
+		Host:   endpoint.Hostname(),
+		Port:   endpoint.Port(),
+		Path:   cloudcraft.DefaultPath,
+		Key:    "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		RetryPolicy: &cloudcraft.RetryPolicy{
+			IsRetryable: func(resp *http.Response, err error) bool {
+				classified.Add(1)
+
+				return cloudcraft.DefaultIsRetryable(resp, err)
+			},
+			Jitter:        cloudcraft.NoJitter,
+			MinRetryDelay: time.Hour,
+			MaxRetryDelay: time.Hour,
+		},
+	}
+
+	client, err := cloudcraft.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// The exponential backoff would wait for an hour, so the request can only
+	// succeed in time if the Retry-After header is honored.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, _, err = client.User.Me(ctx); err != nil {
+		t.Fatalf("UserService.Me() error = %v", err)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	if got := classified.Load(); got != 2 {
+		t.Errorf("IsRetryable calls = %d, want 2", got)
+	}
+}
+
+func TestClient_RetryError(t *testing.T) {
+	t.Parallel()
+
+	const backoff = 200 * time.Millisecond
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"Service Unavailable","message":"Down for maintenance"}`))
+	}))
+	defer ts.Close()
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint:   ts.URL,
+		Key:        "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		MaxRetries: 1,
+		RetryPolicy: &cloudcraft.RetryPolicy{
+			Jitter:        cloudcraft.NoJitter,
+			MinRetryDelay: backoff,
+			MaxRetryDelay: backoff,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	start := time.Now()
+
+	_, resp, err := client.User.Me(context.Background())
+
+	// The last attempt is not followed by a backoff.
+	if elapsed := time.Since(start); elapsed >= 2*backoff {
+		t.Errorf("User.Me() took %v, want less than %v", elapsed, 2*backoff)
+	}
+
+	if !errors.Is(err, cloudcraft.ErrMaxRetriesExceeded) || !cloudcraft.IsServerError(err) {
+		t.Fatalf("User.Me() error = %v, want %v and a server error", err, cloudcraft.ErrMaxRetriesExceeded)
+	}
+
+	var retryErr *cloudcraft.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("User.Me() error = %T, want a *RetryError", err)
+	}
+
+	want := []cloudcraft.RetryAttempt{
+		{StatusCode: http.StatusServiceUnavailable, Backoff: backoff},
+		{StatusCode: http.StatusServiceUnavailable},
+	}
+
+	if len(retryErr.Attempts) != len(want) {
+		t.Fatalf("RetryError.Attempts = %+v, want %+v", retryErr.Attempts, want)
+	}
+
+	for i := range want {
+		if retryErr.Attempts[i] != want[i] {
+			t.Fatalf("RetryError.Attempts[%d] = %+v, want %+v", i, retryErr.Attempts[i], want[i])
+		}
+	}
+
+	// The body of the last response is kept.
+	var apiErr *cloudcraft.APIError
+	if !errors.As(err, &apiErr) || apiErr.Payload == nil || apiErr.Payload.Message != "Down for maintenance" {
+		t.Fatalf("User.Me() error = %v, want an *APIError with the response payload", err)
+	}
+
+	if resp == nil || resp.Status != http.StatusServiceUnavailable || len(resp.Body) == 0 {
+		t.Fatalf("User.Me() response = %+v, want the last error response", resp)
+	}
+}
+
+func TestClient_RetryError_Transport(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	cfg := &cloudcraft.Config{
+		Endpoint:   ts.URL,
+		Key:        "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		MaxRetries: 2,
+	}
+
+	fastRetries(cfg)
+
+	client, err := cloudcraft.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, _, err = client.User.Me(context.Background())
+
+	var retryErr *cloudcraft.RetryError
+	if !errors.As(err, &retryErr) || !errors.Is(err, cloudcraft.ErrMaxRetriesExceeded) {
+		t.Fatalf("User.Me() error = %v, want a *RetryError", err)
+	}
+
+	if len(retryErr.Attempts) != 3 {
+		t.Fatalf("RetryError.Attempts = %+v, want 3 attempts", retryErr.Attempts)
+	}
+
+	for i, attempt := range retryErr.Attempts {
+		if attempt.Err == nil || attempt.StatusCode != 0 {
+			t.Fatalf("RetryError.Attempts[%d] = %+v, want a transport error", i, attempt)
+		}
+	}
+
+	if !errors.Is(err, retryErr.Attempts[2].Err) {
+		t.Fatalf("User.Me() error = %v, want it to wrap the last transport error", err)
+	}
+}
+
+func TestClient_RetryError_NoRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client, err := cloudcraft.NewClient(&cloudcraft.Config{
+		Endpoint: ts.URL,
+		Key:      "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := cloudcraft.ContextWithRequestOptions(context.Background(), cloudcraft.WithMaxRetries(0))
+
+	// A call that is not retried fails with the error of its only attempt.
+	_, _, err = client.User.Me(ctx)
+	if !cloudcraft.IsServerError(err) || errors.Is(err, cloudcraft.ErrMaxRetriesExceeded) {
+		t.Fatalf("User.Me() error = %v, want the error of the only attempt", err)
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("calls to the API = %d, want 1", got)
+	}
+}
+
+func TestClient_RetryBudget(t *testing.T) {
+	t.Parallel()
+
+	var (
+		down     atomic.Bool
+		accepted atomic.Bool
+		calls    atomic.Int32
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasSuffix(r.URL.Path, "/broken"):
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.HasSuffix(r.URL.Path, "/pending") && !accepted.Swap(true):
+			w.WriteHeader(http.StatusAccepted)
+		case down.Load():
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"Service Unavailable"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+
+	cfg := &cloudcraft.Config{
+		Endpoint:    ts.URL,
+		Key:         "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+		MaxRetries:  3,
+		RetryBudget: &cloudcraft.RetryBudget{MaxTokens: 2, TokenRatio: 1},
+	}
+
+	fastRetries(cfg)
+
+	client, err := cloudcraft.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+
+	down.Store(true)
+
+	// The first call spends the whole budget, the second one has none left.
+	for i, wantAttempts := range []int{3, 1} {
+		_, resp, err := client.User.Me(ctx)
+
+		if !cloudcraft.IsServerError(err) ||
+			!errors.Is(err, cloudcraft.ErrRetryBudgetExhausted) ||
+			errors.Is(err, cloudcraft.ErrMaxRetriesExceeded) {
+			t.Fatalf("call #%d: User.Me() error = %v, want %v", i+1, err, cloudcraft.ErrRetryBudgetExhausted)
+		}
+
+		var retryErr *cloudcraft.RetryError
+		if !errors.As(err, &retryErr) || len(retryErr.Attempts) != wantAttempts {
+			t.Fatalf("call #%d: User.Me() error = %v, want a *RetryError with %d attempts", i+1, err, wantAttempts)
+		}
+
+		if !resp.CallStats.RetryBudgetExhausted || resp.CallStats.Attempts != wantAttempts {
+			t.Fatalf("call #%d: CallStats = %+v, want %d attempts and an exhausted budget", i+1, resp.CallStats, wantAttempts)
+		}
+	}
+
+	if got := calls.Load(); got != 4 {
+		t.Fatalf("calls to the API = %d, want 4", got)
+	}
+
+	// Error responses that are not retried do not refill the budget.
+	for _, id := range []string{"missing", "broken"} {
+		if _, _, err = client.Blueprint.Get(ctx, id); err == nil {
+			t.Fatalf("Blueprint.Get(%q) error = nil, want an error", id)
+		}
+	}
+
+	if got := client.RetryBudgetStats().Tokens; got != 0 {
+		t.Fatalf("RetryBudgetStats().Tokens = %v after error responses, want 0", got)
+	}
+
+	// Successes refill the budget.
+	down.Store(false)
+
+	if _, _, err = client.User.Me(ctx); err != nil {
+		t.Fatalf("User.Me() error = %v", err)
+	}
+
+	want := cloudcraft.RetryBudgetStats{Tokens: 1, Retries: 2, Denied: 2}
+
+	if got := client.RetryBudgetStats(); got != want {
+		t.Fatalf("RetryBudgetStats() = %+v, want %+v", got, want)
+	}
+
+	// Successful attempts refill the budget even when they are retried, such
+	// as a 202 Accepted.
+	if _, _, err = client.Blueprint.Get(ctx, "pending"); err != nil {
+		t.Fatalf("Blueprint.Get() error = %v", err)
+	}
+
+	want = cloudcraft.RetryBudgetStats{Tokens: 2, Retries: 3, Denied: 2}
+
+	if got := client.RetryBudgetStats(); got != want {
+		t.Fatalf("RetryBudgetStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRetryBudget_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		give cloudcraft.RetryBudget
+		want error
+	}{
+		{
+			name: "Defaults",
+			give: cloudcraft.RetryBudget{},
+			want: nil,
+		},
+		{
+			name: "Valid settings",
+			give: cloudcraft.RetryBudget{MaxTokens: 20, TokenRatio: 0.5},
+			want: nil,
+		},
+		{
+			name: "Negative ratio",
+			give: cloudcraft.RetryBudget{TokenRatio: -0.1},
+			want: cloudcraft.ErrInvalidRetryBudget,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.give.Validate(); !errors.Is(err, tt.want) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
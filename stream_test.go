@@ -0,0 +1,214 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+func TestClient_Streaming(t *testing.T) {
+	t.Parallel()
+
+	var (
+		imageData  = xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "export-image-valid.png"))
+		budgetData = xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "export-budget-valid.csv"))
+		ctx        = context.Background()
+	)
+
+	const id = "0f1a4e20-a887-4467-a37b-1bc7a3deb9a9"
+
+	var (
+		exportImage = func(client *cloudcraft.Client, w io.Writer) (*cloudcraft.Response, error) {
+			return client.Blueprint.ExportImageTo(ctx, w, id, "png", nil)
+		}
+		exportBudget = func(client *cloudcraft.Client, w io.Writer) (*cloudcraft.Response, error) {
+			return client.Blueprint.ExportBudgetTo(ctx, w, id, "csv", nil)
+		}
+		awsSnapshot = func(client *cloudcraft.Client, w io.Writer) (*cloudcraft.Response, error) {
+			return client.AWS.SnapshotTo(ctx, w, id, "us-east-1", "png", nil)
+		}
+		azureSnapshot = func(client *cloudcraft.Client, w io.Writer) (*cloudcraft.Response, error) {
+			return client.Azure.SnapshotTo(ctx, w, id, "eastus", "png", nil)
+		}
+	)
+
+	tests := []struct {
+		name            string
+		handler         http.HandlerFunc
+		call            func(client *cloudcraft.Client, w io.Writer) (*cloudcraft.Response, error)
+		nilWriter       bool
+		maxResponseSize int64
+		wantBody        []byte
+		wantStatus      int
+		wantErr         error
+	}{
+		{
+			name:       "Blueprint image export",
+			handler:    writeBody(imageData),
+			call:       exportImage,
+			wantBody:   imageData,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Blueprint budget export",
+			handler:    writeBody(budgetData),
+			call:       exportBudget,
+			wantBody:   budgetData,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "AWS snapshot",
+			handler:    writeBody(imageData),
+			call:       awsSnapshot,
+			wantBody:   imageData,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Azure snapshot",
+			handler:    writeBody(imageData),
+			call:       azureSnapshot,
+			wantBody:   imageData,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "Response of exactly the maximum size",
+			handler:         writeChunkedBody(imageData),
+			call:            exportImage,
+			maxResponseSize: int64(len(imageData)),
+			wantBody:        imageData,
+			wantStatus:      http.StatusOK,
+		},
+		{
+			name:            "Content-Length over the maximum size",
+			handler:         writeBody(imageData),
+			call:            exportImage,
+			maxResponseSize: 16,
+			wantStatus:      http.StatusOK,
+			wantErr:         cloudcraft.ErrResponseTooLarge,
+		},
+		{
+			name:            "Chunked response over the maximum size",
+			handler:         writeChunkedBody(imageData),
+			call:            awsSnapshot,
+			maxResponseSize: 16,
+			wantStatus:      http.StatusOK,
+			wantErr:         cloudcraft.ErrResponseTooLarge,
+		},
+		{
+			name: "API error response",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			call:       azureSnapshot,
+			wantStatus: http.StatusNotFound,
+			wantErr:    cloudcraft.ErrNotFound,
+		},
+		{
+			name:      "Nil writer",
+			handler:   func(_ http.ResponseWriter, _ *http.Request) {},
+			call:      exportBudget,
+			nilWriter: true,
+			wantErr:   cloudcraft.ErrNilWriter,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			endpoint, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+				cfg.MaxResponseSize = tt.maxResponseSize
+			})
+
+			var (
+				buf bytes.Buffer
+				w   io.Writer = &buf
+			)
+
+			if tt.nilWriter {
+				w = nil
+			}
+
+			resp, err := tt.call(client, w)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("call error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantStatus == 0 {
+				if resp != nil {
+					t.Fatalf("call response = %v, want nil", resp)
+				}
+
+				return
+			}
+
+			if resp == nil {
+				t.Fatal("call response = nil")
+			}
+
+			if resp.Status != tt.wantStatus {
+				t.Fatalf("call status = %d, want %d", resp.Status, tt.wantStatus)
+			}
+
+			if len(resp.Body) != 0 {
+				t.Fatalf("call response body = %d bytes, want empty", len(resp.Body))
+			}
+
+			if tt.wantErr == nil && !bytes.Equal(buf.Bytes(), tt.wantBody) {
+				t.Fatalf("call wrote %d bytes, want %d", buf.Len(), len(tt.wantBody))
+			}
+
+			if buf.Len() > int(tt.maxResponseSize) && tt.maxResponseSize > 0 {
+				t.Fatalf("call wrote %d bytes, more than the maximum of %d", buf.Len(), tt.maxResponseSize)
+			}
+		})
+	}
+}
+
+// writeBody returns a handler that writes body with a Content-Length header.
+func writeBody(body []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusOK)
+
+		w.Write(body)
+	}
+}
+
+// writeChunkedBody returns a handler that writes body without a Content-Length
+// header.
+func writeChunkedBody(body []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		w.Write(body)
+	}
+}
@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/DataDog/cloudcraft-go/internal/xerrors"
+)
+
+const (
+	// ErrInvalidTLSConfig is returned when a Config is created with a TLS
+	// configuration that cannot be loaded. It wraps the cause of the failure.
+	ErrInvalidTLSConfig xerrors.Error = "invalid TLS config"
+
+	// ErrInvalidCACertificate is returned when the root CAs of a TLSConfig
+	// hold no valid PEM-encoded certificate.
+	ErrInvalidCACertificate xerrors.Error = "no valid CA certificate found in PEM data"
+
+	// ErrIncompleteClientCertificate is returned when a TLSConfig sets a
+	// client certificate without its private key, or the other way around.
+	ErrIncompleteClientCertificate xerrors.Error = "client certificate and key must be set together"
+
+	// ErrUnsupportedTLSVersion is returned when a TLSConfig sets a minimum
+	// TLS version other than TLS 1.2 or TLS 1.3.
+	ErrUnsupportedTLSVersion xerrors.Error = "unsupported minimum TLS version; must be TLS 1.2 or TLS 1.3"
+
+	// ErrUnsupportedTransport is returned when a TLSConfig is set along with a
+	// transport that is not an *http.Transport, which it cannot be applied to.
+	ErrUnsupportedTransport xerrors.Error = "TLS config requires an *http.Transport"
+)
+
+// TLSConfig holds the TLS settings used to connect to the Cloudcraft API, for
+// deployments behind a TLS-intercepting proxy or with a private CA.
+//
+// PEM data and files can be combined: the certificates of both are used.
+type TLSConfig struct {
+	// RootCAs holds PEM-encoded CA certificates trusted in addition to the
+	// system roots.
+	RootCAs []byte
+
+	// ClientCertificate and ClientKey hold the PEM-encoded certificate and
+	// private key presented to the server for mutual TLS.
+	ClientCertificate []byte
+	ClientKey         []byte
+
+	// RootCAFile is the path to a PEM file of CA certificates trusted in
+	// addition to the system roots.
+	RootCAFile string
+
+	// ClientCertificateFile and ClientKeyFile are the paths to the
+	// PEM-encoded certificate and private key presented to the server for
+	// mutual TLS. They are used when ClientCertificate and ClientKey are not
+	// set.
+	ClientCertificateFile string
+	ClientKeyFile         string
+
+	// ServerName overrides the host name used to verify the certificate of
+	// the server, for endpoints reached through an address that the
+	// certificate does not cover.
+	ServerName string
+
+	// MinVersion is the minimum TLS version accepted, either tls.VersionTLS12
+	// or tls.VersionTLS13.
+	//
+	// If not set, the default value is tls.VersionTLS13.
+	MinVersion uint16
+}
+
+// Validate checks that the TLSConfig is valid by loading its certificates.
+func (t *TLSConfig) Validate() error {
+	if _, err := t.apply(nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// apply returns a copy of base with the settings of the TLSConfig applied. If
+// base is nil, the defaults of the client are used.
+func (t *TLSConfig) apply(base *tls.Config) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS13}
+	if base != nil {
+		cfg = base.Clone()
+	}
+
+	switch t.MinVersion {
+	case 0:
+	case tls.VersionTLS12, tls.VersionTLS13:
+		cfg.MinVersion = t.MinVersion
+	default:
+		return nil, fmt.Errorf("%w: %w: %#04x", ErrInvalidTLSConfig, ErrUnsupportedTLSVersion, t.MinVersion)
+	}
+
+	if t.ServerName != "" {
+		cfg.ServerName = t.ServerName
+	}
+
+	roots, err := t.rootCAs()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTLSConfig, err)
+	}
+
+	if roots != nil {
+		cfg.RootCAs = roots
+	}
+
+	certificate, err := t.clientCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTLSConfig, err)
+	}
+
+	if certificate != nil {
+		cfg.Certificates = []tls.Certificate{*certificate}
+	}
+
+	return cfg, nil
+}
+
+// rootCAs returns the system roots with the extra CA certificates added, or
+// nil if there are none.
+func (t *TLSConfig) rootCAs() (*x509.CertPool, error) {
+	if len(t.RootCAs) == 0 && t.RootCAFile == "" {
+		return nil, nil //nolint:nilnil // No extra CA certificates to add.
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if len(t.RootCAs) > 0 && !pool.AppendCertsFromPEM(t.RootCAs) {
+		return nil, fmt.Errorf("%w: RootCAs", ErrInvalidCACertificate)
+	}
+
+	if t.RootCAFile != "" {
+		data, err := os.ReadFile(t.RootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCACertificate, t.RootCAFile)
+		}
+	}
+
+	return pool, nil
+}
+
+// clientCertificate returns the client certificate presented for mutual TLS,
+// or nil if there is none.
+func (t *TLSConfig) clientCertificate() (*tls.Certificate, error) {
+	certificate, key := t.ClientCertificate, t.ClientKey
+
+	if len(certificate) == 0 && len(key) == 0 {
+		if t.ClientCertificateFile == "" && t.ClientKeyFile == "" {
+			return nil, nil //nolint:nilnil // No client certificate to present.
+		}
+
+		if t.ClientCertificateFile == "" || t.ClientKeyFile == "" {
+			return nil, ErrIncompleteClientCertificate
+		}
+
+		pair, err := tls.LoadX509KeyPair(t.ClientCertificateFile, t.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		return &pair, nil
+	}
+
+	if len(certificate) == 0 || len(key) == 0 {
+		return nil, ErrIncompleteClientCertificate
+	}
+
+	pair, err := tls.X509KeyPair(certificate, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return &pair, nil
+}
+
+// applyTLS returns transport with the TLS settings of cfg applied, leaving the
+// original transport untouched.
+func applyTLS(transport http.RoundTripper, cfg *TLSConfig) (http.RoundTripper, error) {
+	base, ok := transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("%w: %w, got %T", ErrInvalidTLSConfig, ErrUnsupportedTransport, transport)
+	}
+
+	tlsConfig, err := cfg.apply(base.TLSClientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := base.Clone()
+	clone.TLSClientConfig = tlsConfig
+
+	return clone, nil
+}
@@ -0,0 +1,256 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/cloudcraft-go"
+)
+
+func TestClient_TLS(t *testing.T) {
+	t.Parallel()
+
+	clientCert, clientKey := newTestCertificate(t)
+
+	clientPool := x509.NewCertPool()
+	if !clientPool.AppendCertsFromPEM(clientCert) {
+		t.Fatal("failed to parse the client certificate")
+	}
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	ts.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientPool,
+		MinVersion: tls.VersionTLS12,
+	}
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0) // Rejected handshakes are expected.
+	ts.StartTLS()
+
+	// The server must outlive the parallel subtests.
+	t.Cleanup(ts.Close)
+
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, serverCA, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		give    *cloudcraft.TLSConfig
+		wantErr bool
+	}{
+		{
+			name: "CA bytes and client certificate",
+			give: &cloudcraft.TLSConfig{
+				RootCAs:           serverCA,
+				ClientCertificate: clientCert,
+				ClientKey:         clientKey,
+			},
+		},
+		{
+			name: "CA file, server name and minimum version",
+			give: &cloudcraft.TLSConfig{
+				RootCAFile:        caFile,
+				ClientCertificate: clientCert,
+				ClientKey:         clientKey,
+				ServerName:        "example.com",
+				MinVersion:        tls.VersionTLS12,
+			},
+		},
+		{
+			name: "Unknown CA",
+			give: &cloudcraft.TLSConfig{
+				ClientCertificate: clientCert,
+				ClientKey:         clientKey,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Server name not covered by the certificate",
+			give: &cloudcraft.TLSConfig{
+				RootCAs:           serverCA,
+				ClientCertificate: clientCert,
+				ClientKey:         clientKey,
+				ServerName:        "cloudcraft.invalid",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing client certificate",
+			give: &cloudcraft.TLSConfig{
+				RootCAs: serverCA,
+			},
+			wantErr: true,
+		},
+	}
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &cloudcraft.Config{
+				Scheme: endpoint.Scheme,
+				Host:   endpoint.Hostname(),
+				Port:   endpoint.Port(),
+				Path:   cloudcraft.DefaultPath,
+				Key:    "not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=",
+				TLS:    tt.give,
+			}
+
+			fastRetries(cfg)
+
+			client, err := cloudcraft.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, _, err = client.User.Me(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("User.Me() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	cert, key := newTestCertificate(t)
+
+	tests := []struct {
+		name string
+		give *cloudcraft.TLSConfig
+		want error
+	}{
+		{
+			name: "Valid configuration",
+			give: &cloudcraft.TLSConfig{RootCAs: cert, ClientCertificate: cert, ClientKey: key},
+			want: nil,
+		},
+		{
+			name: "Invalid CA certificate",
+			give: &cloudcraft.TLSConfig{RootCAs: []byte("not a certificate")},
+			want: cloudcraft.ErrInvalidCACertificate,
+		},
+		{
+			name: "Missing CA file",
+			give: &cloudcraft.TLSConfig{RootCAFile: filepath.Join(t.TempDir(), "missing.pem")},
+			want: os.ErrNotExist,
+		},
+		{
+			name: "Client certificate without key",
+			give: &cloudcraft.TLSConfig{ClientCertificate: cert},
+			want: cloudcraft.ErrIncompleteClientCertificate,
+		},
+		{
+			name: "Client key file without certificate file",
+			give: &cloudcraft.TLSConfig{ClientKeyFile: "client.key"},
+			want: cloudcraft.ErrIncompleteClientCertificate,
+		},
+		{
+			name: "Unsupported minimum version",
+			give: &cloudcraft.TLSConfig{MinVersion: tls.VersionTLS10},
+			want: cloudcraft.ErrUnsupportedTLSVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.give.Validate()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.want)
+			}
+
+			if tt.want != nil && !errors.Is(err, cloudcraft.ErrInvalidTLSConfig) {
+				t.Fatalf("Validate() error = %v, want it to wrap %v", err, cloudcraft.ErrInvalidTLSConfig)
+			}
+		})
+	}
+}
+
+func TestNewClient_TLSUnsupportedTransport(t *testing.T) {
+	t.Parallel()
+
+	cfg := cloudcraft.NewConfig("not-a-real-key-oRbwhd5RTvWsPJ89ZkASHU13qcyd=")
+	cfg.Transport = cloudcraft.RoundTripperFunc(http.DefaultTransport.RoundTrip)
+	cfg.TLS = &cloudcraft.TLSConfig{MinVersion: tls.VersionTLS12}
+
+	if _, err := cloudcraft.NewClient(cfg); !errors.Is(err, cloudcraft.ErrUnsupportedTransport) {
+		t.Fatalf("NewClient() error = %v, want %v", err, cloudcraft.ErrUnsupportedTransport)
+	}
+}
+
+// newTestCertificate returns a PEM-encoded self-signed certificate and its
+// private key.
+func newTestCertificate(t *testing.T) (cert, key []byte) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cloudcraft-go test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return cert, key
+}
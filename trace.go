@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Attribute keys set on the spans created by the client.
+const (
+	// AttributeService is the Cloudcraft API service called, one of "AWS",
+	// "Azure", "Blueprint" or "User".
+	AttributeService string = "cloudcraft.service"
+
+	// AttributeOperation is the operation called, named after the method of
+	// the service, such as "Get" or "Snapshot".
+	AttributeOperation string = "cloudcraft.operation"
+
+	// AttributeResourceID is the ID of the blueprint or account the operation
+	// applies to, if any.
+	AttributeResourceID string = "cloudcraft.resource.id"
+
+	// AttributeAttempt is the number of an attempt, starting at 1. It is set
+	// on attempt spans.
+	AttributeAttempt string = "cloudcraft.attempt"
+
+	// AttributeAttempts is the number of attempts made for a call. It is set
+	// on call spans.
+	AttributeAttempts string = "cloudcraft.attempts"
+
+	// AttributeHTTPMethod is the HTTP method of the request.
+	AttributeHTTPMethod string = "http.request.method"
+
+	// AttributeHTTPStatusCode is the HTTP status code of the response.
+	AttributeHTTPStatusCode string = "http.response.status_code"
+
+	// AttributeURL is the full URL of the request.
+	AttributeURL string = "url.full"
+)
+
+// Service names used as values of AttributeService.
+const (
+	serviceAWS       string = "AWS"
+	serviceAzure     string = "Azure"
+	serviceBlueprint string = "Blueprint"
+	serviceUser      string = "User"
+)
+
+// Tracer creates the spans describing the calls made by a Client.
+//
+// Each logical API call, such as BlueprintService.Get, gets one span named
+// after the service and operation, for example "Blueprint.Get", with one child
+// span per attempt named after the HTTP method. Retries therefore show up as
+// sibling attempt spans under the same call span.
+//
+// The package has no dependency on a tracing library; see the otelcloudcraft
+// module for an OpenTelemetry implementation.
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx, if any, and returns a
+	// context holding the new span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a unit of work started by a Tracer.
+type Span interface {
+	// SetAttributes sets attributes on the span.
+	SetAttributes(attrs ...Attribute)
+
+	// Inject writes the headers that propagate the span to the Cloudcraft
+	// API, such as the W3C traceparent header, to header.
+	Inject(header http.Header)
+
+	// End completes the span. If err is not nil, the span failed with err.
+	End(err error)
+}
+
+// Attribute is a key-value pair describing a span. Values are of type string,
+// int or bool.
+type Attribute struct {
+	Value any
+	Key   string
+}
+
+// operation identifies a logical call to the Cloudcraft API for observability
+// purposes.
+type operation struct {
+	service    string
+	name       string
+	resourceID string
+}
+
+// operationContextKey is the context key for the operation of a request.
+type operationContextKey struct{}
+
+// withOperation returns a copy of ctx that identifies the requests made with
+// it as part of the given operation.
+func withOperation(ctx context.Context, service, name, resourceID string) context.Context {
+	return context.WithValue(ctx, operationContextKey{}, operation{
+		service:    service,
+		name:       name,
+		resourceID: resourceID,
+	})
+}
+
+// operationFrom returns the operation stored in ctx, if any.
+func operationFrom(ctx context.Context) operation {
+	op, _ := ctx.Value(operationContextKey{}).(operation)
+
+	return op
+}
+
+// spanName returns the name of the span of the operation.
+func (op operation) spanName() string {
+	if op.service == "" {
+		return "Cloudcraft"
+	}
+
+	return op.service + "." + op.name
+}
+
+// attributes returns the span attributes describing the operation.
+func (op operation) attributes() []Attribute {
+	attrs := make([]Attribute, 0, 3)
+
+	if op.service != "" {
+		attrs = append(attrs,
+			Attribute{Key: AttributeService, Value: op.service},
+			Attribute{Key: AttributeOperation, Value: op.name},
+		)
+	}
+
+	if op.resourceID != "" {
+		attrs = append(attrs, Attribute{Key: AttributeResourceID, Value: op.resourceID})
+	}
+
+	return attrs
+}
+
+// startAttempt starts the span of an attempt to send req, injects it into the
+// headers of req and returns req with the span in its context.
+func (c *Client) startAttempt(req *http.Request, attempt int) (*http.Request, Span) {
+	ctx, span := c.tracer.Start(req.Context(), req.Method,
+		Attribute{Key: AttributeAttempt, Value: attempt},
+		Attribute{Key: AttributeHTTPMethod, Value: req.Method},
+		Attribute{Key: AttributeURL, Value: req.URL.String()},
+	)
+
+	span.Inject(req.Header)
+
+	return req.WithContext(ctx), span
+}
+
+// endAttempt completes the span of an attempt given its outcome.
+func endAttempt(span Span, resp *http.Response, err error) {
+	if err != nil {
+		span.End(err)
+
+		return
+	}
+
+	span.SetAttributes(Attribute{Key: AttributeHTTPStatusCode, Value: resp.StatusCode})
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.End(fmt.Errorf("%w %d", ErrRequestFailed, resp.StatusCode))
+
+		return
+	}
+
+	span.End(nil)
+}
+
+// noopTracer is the Tracer used when none is configured.
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// noopSpan is the Span created by noopTracer.
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(_ ...Attribute) {}
+
+func (noopSpan) Inject(_ http.Header) {}
+
+func (noopSpan) End(_ error) {}
@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed under the Apache-2.0 License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-Present Datadog, Inc.
+
+package cloudcraft_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/DataDog/cloudcraft-go"
+	"github.com/DataDog/cloudcraft-go/internal/xtesting"
+)
+
+type recordedSpan struct {
+	attrs  map[string]any
+	err    error
+	name   string
+	parent int
+	id     int
+	ended  bool
+}
+
+type spanContextKey struct{}
+
+// recordingTracer is a Tracer that records the spans it creates.
+type recordingTracer struct {
+	spans []*recordedSpan
+	mu    sync.Mutex
+}
+
+func (r *recordingTracer) Start(
+	ctx context.Context,
+	name string,
+	attrs ...cloudcraft.Attribute,
+) (context.Context, cloudcraft.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parent, _ := ctx.Value(spanContextKey{}).(int)
+
+	span := &recordedSpan{
+		attrs:  make(map[string]any),
+		name:   name,
+		parent: parent,
+		id:     len(r.spans) + 1,
+	}
+
+	r.spans = append(r.spans, span)
+
+	handle := &recordingSpan{tracer: r, span: span}
+	handle.SetAttributes(attrs...)
+
+	return context.WithValue(ctx, spanContextKey{}, span.id), handle
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	span   *recordedSpan
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...cloudcraft.Attribute) {
+	for _, attr := range attrs {
+		s.span.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordingSpan) Inject(header http.Header) {
+	header.Set("Traceparent", fmt.Sprintf("00-%032x-%016x-01", 1, s.span.id))
+}
+
+func (s *recordingSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.span.ended = true
+	s.span.err = err
+}
+
+func TestClient_Tracer(t *testing.T) {
+	t.Parallel()
+
+	validTestData := xtesting.ReadFile(t, filepath.Join(_testBlueprintDataPath, "get-valid.json"))
+
+	const id = "0f1a4e20-a887-4467-a37b-1bc7a3deb9a9"
+
+	var (
+		mu           sync.Mutex
+		traceparents []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparents = append(traceparents, r.Header.Get("Traceparent"))
+		attempt := len(traceparents)
+		mu.Unlock()
+
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		w.Write(validTestData)
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracer := &recordingTracer{}
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		fastRetries(cfg)
+
+		cfg.Tracer = tracer
+	})
+
+	if _, _, err = client.Blueprint.Get(context.Background(), id); err != nil {
+		t.Fatalf("Blueprint.Get() error = %v", err)
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(tracer.spans))
+	}
+
+	call, first, second := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+
+	wantCallAttrs := map[string]any{
+		cloudcraft.AttributeService:        "Blueprint",
+		cloudcraft.AttributeOperation:      "Get",
+		cloudcraft.AttributeResourceID:     id,
+		cloudcraft.AttributeAttempts:       2,
+		cloudcraft.AttributeHTTPStatusCode: http.StatusOK,
+	}
+
+	if call.name != "Blueprint.Get" || call.parent != 0 || !call.ended || call.err != nil {
+		t.Fatalf("call span = %+v", call)
+	}
+
+	if !reflect.DeepEqual(call.attrs, wantCallAttrs) {
+		t.Fatalf("call span attributes = %v, want %v", call.attrs, wantCallAttrs)
+	}
+
+	for i, tt := range []struct {
+		span       *recordedSpan
+		wantStatus int
+		wantErr    bool
+	}{
+		{span: first, wantStatus: http.StatusServiceUnavailable, wantErr: true},
+		{span: second, wantStatus: http.StatusOK, wantErr: false},
+	} {
+		if tt.span.name != http.MethodGet || tt.span.parent != call.id || !tt.span.ended {
+			t.Fatalf("attempt span %d = %+v", i+1, tt.span)
+		}
+
+		if tt.span.attrs[cloudcraft.AttributeAttempt] != i+1 {
+			t.Fatalf("attempt span %d attempt = %v", i+1, tt.span.attrs[cloudcraft.AttributeAttempt])
+		}
+
+		if tt.span.attrs[cloudcraft.AttributeHTTPStatusCode] != tt.wantStatus {
+			t.Fatalf("attempt span %d status = %v, want %d",
+				i+1, tt.span.attrs[cloudcraft.AttributeHTTPStatusCode], tt.wantStatus)
+		}
+
+		if (tt.span.err != nil) != tt.wantErr {
+			t.Fatalf("attempt span %d error = %v, wantErr %v", i+1, tt.span.err, tt.wantErr)
+		}
+
+		want := fmt.Sprintf("00-%032x-%016x-01", 1, tt.span.id)
+		if traceparents[i] != want {
+			t.Fatalf("attempt %d traceparent = %q, want %q", i+1, traceparents[i], want)
+		}
+	}
+}
+
+func TestClient_Tracer_Error(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	endpoint, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracer := &recordingTracer{}
+
+	client := xtesting.SetupMockClientWithConfig(t, endpoint, func(cfg *cloudcraft.Config) {
+		cfg.Tracer = tracer
+	})
+
+	_, err = client.AWS.Delete(context.Background(), "account-id")
+	if !errors.Is(err, cloudcraft.ErrNotFound) {
+		t.Fatalf("AWS.Delete() error = %v, want %v", err, cloudcraft.ErrNotFound)
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(tracer.spans))
+	}
+
+	call := tracer.spans[0]
+
+	if call.name != "AWS.Delete" || !errors.Is(call.err, cloudcraft.ErrNotFound) {
+		t.Fatalf("call span = %+v", call)
+	}
+
+	if call.attrs[cloudcraft.AttributeHTTPStatusCode] != http.StatusNotFound ||
+		call.attrs[cloudcraft.AttributeResourceID] != "account-id" ||
+		call.attrs[cloudcraft.AttributeAttempts] != 1 {
+		t.Fatalf("call span attributes = %v", call.attrs)
+	}
+}
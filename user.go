@@ -52,19 +52,21 @@ func (s *UserService) Me(ctx context.Context) (*User, *Response, error) {
 	endpoint.WriteString(userPath)
 	endpoint.WriteString("/me")
 
-	req, err := s.client.request(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	opCtx := withOperation(ctx, serviceUser, "Me", "")
+
+	req, err := s.client.request(opCtx, http.MethodGet, endpoint.String(), http.NoBody)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	ret, err := s.client.do(req)
 	if err != nil {
-		return nil, nil, err
+		return nil, ret, err
 	}
 
 	var user *User
 	if err := json.Unmarshal(ret.Body, &user); err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, ret, fmt.Errorf("%w", err)
 	}
 
 	return user, ret, nil